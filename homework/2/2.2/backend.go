@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BackendKind 已准入任务的执行后端
+type BackendKind int
+
+const (
+	// BackendGoroutinePerTask 每个任务启动一个新协程（默认）
+	BackendGoroutinePerTask BackendKind = iota
+	// BackendWorkStealing 固定数量的工作协程，各自维护双端队列，空闲时随机挑选其他协程窃取任务
+	BackendWorkStealing
+)
+
+func (k BackendKind) String() string {
+	switch k {
+	case BackendGoroutinePerTask:
+		return "goroutine-per-task"
+	case BackendWorkStealing:
+		return "work-stealing"
+	default:
+		return fmt.Sprintf("BackendKind(%d)", int(k))
+	}
+}
+
+// WithBackend 选择执行后端；workers 仅对工作池类后端有效，<=0 时使用 GOMAXPROCS
+func WithBackend(kind BackendKind, workers int) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.backendKind = kind
+		ts.backendWorkers = workers
+	}
+}
+
+// dispatchBackend 执行调度循环已准入的任务
+type dispatchBackend interface {
+	submit(job func())
+	// shutdown 等待所有已提交的任务执行完毕并回收工作协程
+	shutdown()
+	// blocking 由即将阻塞等待的任务调用（例如等待子任务），返回的函数在阻塞结束时调用。
+	// 固定大小的工作池借此临时补充工作协程，避免所有协程都在等待而无人执行子任务
+	blocking() (unblock func())
+}
+
+func newDispatchBackend(kind BackendKind, workers int) dispatchBackend {
+	if kind == BackendWorkStealing {
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		return newWorkStealingBackend(workers)
+	}
+	return &goroutineBackend{}
+}
+
+type goroutineBackend struct {
+	wg sync.WaitGroup
+}
+
+func (b *goroutineBackend) submit(job func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		job()
+	}()
+}
+
+func (b *goroutineBackend) shutdown() {
+	b.wg.Wait()
+}
+
+func (b *goroutineBackend) blocking() func() {
+	return func() {}
+}
+
+// workDeque 工作协程私有的双端队列：所有者从底部存取（LIFO，缓存友好），窃取者从顶部取（FIFO，取走最早的任务）
+type workDeque struct {
+	mu   sync.Mutex
+	jobs []func()
+}
+
+func (d *workDeque) pushBottom(job func()) {
+	d.mu.Lock()
+	d.jobs = append(d.jobs, job)
+	d.mu.Unlock()
+}
+
+func (d *workDeque) popBottom() (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.jobs)
+	if n == 0 {
+		return nil, false
+	}
+	job := d.jobs[n-1]
+	d.jobs[n-1] = nil
+	d.jobs = d.jobs[:n-1]
+	return job, true
+}
+
+func (d *workDeque) stealTop() (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.jobs) == 0 {
+		return nil, false
+	}
+	job := d.jobs[0]
+	d.jobs[0] = nil
+	d.jobs = d.jobs[1:]
+	return job, true
+}
+
+// workStealingBackend 工作窃取执行后端
+// 外部提交的任务轮流放入各工作协程的队列；长任务占住某个协程时，其队列中积压的短任务会被空闲协程窃取
+type workStealingBackend struct {
+	deques []*workDeque
+	next   int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+func newWorkStealingBackend(workers int) *workStealingBackend {
+	b := &workStealingBackend{deques: make([]*workDeque, workers)}
+	b.cond = sync.NewCond(&b.mu)
+	for i := range b.deques {
+		b.deques[i] = &workDeque{}
+	}
+	b.wg.Add(workers)
+	for i := range b.deques {
+		go b.work(i)
+	}
+	return b
+}
+
+func (b *workStealingBackend) submit(job func()) {
+	b.mu.Lock()
+	target := b.next
+	b.next = (b.next + 1) % len(b.deques)
+	b.pending++
+	b.mu.Unlock()
+
+	b.deques[target].pushBottom(job)
+	b.cond.Signal()
+}
+
+func (b *workStealingBackend) shutdown() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.cond.Broadcast()
+	b.wg.Wait()
+}
+
+// blocking 启动一个只窃取任务的补偿协程，顶替阻塞中的工作协程，阻塞结束后补偿协程在当前任务完成时退出
+func (b *workStealingBackend) blocking() func() {
+	var stopped atomic.Bool
+	b.wg.Add(1)
+	go b.compensate(&stopped)
+	return func() {
+		b.mu.Lock()
+		stopped.Store(true)
+		b.mu.Unlock()
+		b.cond.Broadcast()
+	}
+}
+
+func (b *workStealingBackend) compensate(stopped *atomic.Bool) {
+	defer b.wg.Done()
+	for !stopped.Load() {
+		job, ok := b.find(-1)
+		if !ok {
+			b.mu.Lock()
+			for b.pending == 0 && !b.closed && !stopped.Load() {
+				b.cond.Wait()
+			}
+			done := b.pending == 0 && b.closed
+			b.mu.Unlock()
+			if done {
+				return
+			}
+			continue
+		}
+
+		b.mu.Lock()
+		b.pending--
+		b.mu.Unlock()
+		job()
+	}
+}
+
+func (b *workStealingBackend) work(id int) {
+	defer b.wg.Done()
+	for {
+		job, ok := b.find(id)
+		if !ok {
+			b.mu.Lock()
+			for b.pending == 0 && !b.closed {
+				b.cond.Wait()
+			}
+			done := b.pending == 0 && b.closed
+			b.mu.Unlock()
+			if done {
+				return
+			}
+			continue
+		}
+
+		b.mu.Lock()
+		b.pending--
+		b.mu.Unlock()
+		job()
+	}
+}
+
+// find 先取自己队列底部的任务，没有时从随机的其他队列顶部窃取；id 为 -1 时只窃取
+func (b *workStealingBackend) find(id int) (func(), bool) {
+	if id >= 0 {
+		if job, ok := b.deques[id].popBottom(); ok {
+			return job, true
+		}
+	}
+	n := len(b.deques)
+	start := rand.IntN(n)
+	for i := 0; i < n; i++ {
+		victim := (start + i) % n
+		if victim == id {
+			continue
+		}
+		if job, ok := b.deques[victim].stealTop(); ok {
+			return job, true
+		}
+	}
+	return nil, false
+}
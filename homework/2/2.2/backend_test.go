@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// spin 忙等待模拟 CPU 密集型工作
+func spin(d time.Duration) {
+	deadline := time.Now().Add(d)
+	for time.Now().Before(deadline) {
+	}
+}
+
+// BenchmarkDispatchBackends 对比两种执行后端处理长短任务混合负载的性能
+// 每批 jobs 个任务，其中每 50 个有一个 500µs 的长任务，其余为 5µs 的短任务
+func BenchmarkDispatchBackends(b *testing.B) {
+	workload := func(i int) func() {
+		if i%50 == 0 {
+			return func() { spin(500 * time.Microsecond) }
+		}
+		return func() { spin(5 * time.Microsecond) }
+	}
+	for _, jobs := range []int{500, 2000} {
+		for _, kind := range []BackendKind{BackendGoroutinePerTask, BackendWorkStealing} {
+			b.Run(fmt.Sprintf("%s/jobs=%d", kind, jobs), func(b *testing.B) {
+				b.ReportAllocs()
+				for n := 0; n < b.N; n++ {
+					backend := newDispatchBackend(kind, 0)
+					for i := 0; i < jobs; i++ {
+						backend.submit(workload(i))
+					}
+					backend.shutdown()
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*jobs), "ns/task")
+			})
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 任务所属标签的熔断器处于打开状态，任务未执行即快速失败
+var ErrCircuitOpen = errors.New("熔断器已打开，任务快速失败")
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// WithTag 为任务设置分类标签，同一标签的任务共享一个熔断器
+func WithTag(tag string) TaskOption {
+	return func(t *Task) {
+		t.Tag = tag
+	}
+}
+
+// WithCircuitBreaker 为每个任务标签启用熔断器：连续失败 failureThreshold 次后打开，
+// 打开期间该标签的任务快速失败；经过 coolDown 后进入半开状态，放行一个探测任务，
+// 探测成功则关闭熔断器，失败则重新打开。没有标签的任务不受熔断器影响
+func WithCircuitBreaker(failureThreshold int, coolDown time.Duration) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.breakers = newBreakerSet(max(failureThreshold, 1), coolDown)
+	}
+}
+
+// BreakerTransition 熔断器的一次状态变化
+type BreakerTransition struct {
+	Time   time.Time
+	Tag    string
+	From   BreakerState
+	To     BreakerState
+	Reason string
+}
+
+// BreakerStatus 熔断器当前状态快照
+type BreakerStatus struct {
+	Tag              string       `json:"tag"`
+	State            BreakerState `json:"state"`
+	ConsecutiveFails int          `json:"consecutiveFails"`
+	OpenedAt         time.Time    `json:"openedAt,omitzero"`
+	Rejected         int          `json:"rejected"`
+}
+
+type circuitBreaker struct {
+	state            BreakerState
+	consecutiveFails int
+	openedAt         time.Time
+	// probeInFlight/probeTaskID 半开状态下放行的探测任务，只有它的结果能决定熔断器关闭或重新打开
+	probeInFlight bool
+	probeTaskID   int
+	rejected      int
+}
+
+// breakerSet 按标签管理熔断器
+type breakerSet struct {
+	threshold int
+	coolDown  time.Duration
+
+	mu          sync.Mutex
+	breakers    map[string]*circuitBreaker
+	transitions []BreakerTransition
+}
+
+func newBreakerSet(threshold int, coolDown time.Duration) *breakerSet {
+	return &breakerSet{
+		threshold: threshold,
+		coolDown:  coolDown,
+		breakers:  make(map[string]*circuitBreaker),
+	}
+}
+
+func (s *breakerSet) get(tag string) *circuitBreaker {
+	b, ok := s.breakers[tag]
+	if !ok {
+		b = &circuitBreaker{state: BreakerClosed}
+		s.breakers[tag] = b
+	}
+	return b
+}
+
+// transition 切换状态并记录，调用方需持有 s.mu
+func (s *breakerSet) transition(tag string, b *circuitBreaker, to BreakerState, reason string) {
+	s.transitions = append(s.transitions, BreakerTransition{
+		Time:   time.Now(),
+		Tag:    tag,
+		From:   b.state,
+		To:     to,
+		Reason: reason,
+	})
+	fmt.Printf("🔌 熔断器 [%s] %s -> %s: %s\n", tag, b.state, to, reason)
+	b.state = to
+}
+
+// allow 判断标签为 tag 的任务能否执行；半开状态下只放行一个探测任务并记录其ID
+func (s *breakerSet) allow(tag string, taskID int) bool {
+	if s == nil || tag == "" {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.get(tag)
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < s.coolDown {
+			b.rejected++
+			return false
+		}
+		s.transition(tag, b, BreakerHalfOpen, fmt.Sprintf("冷却 %v 结束，放行探测任务", s.coolDown))
+		b.probeInFlight, b.probeTaskID = true, taskID
+		return true
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			b.rejected++
+			return false
+		}
+		b.probeInFlight, b.probeTaskID = true, taskID
+		return true
+	default:
+		return true
+	}
+}
+
+// record 记录任务执行结果并驱动状态变化。
+// 半开状态下只有探测任务的结果能改变状态，打开前已在运行的任务此时结束不影响判断
+func (s *breakerSet) record(tag string, taskID int, err error) {
+	if s == nil || tag == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.get(tag)
+	probe := b.state == BreakerHalfOpen && b.probeInFlight && b.probeTaskID == taskID
+	if err == nil {
+		b.consecutiveFails = 0
+		if probe {
+			b.probeInFlight = false
+			s.transition(tag, b, BreakerClosed, fmt.Sprintf("探测任务 %d 成功", taskID))
+		}
+		return
+	}
+
+	b.consecutiveFails++
+	switch {
+	case probe:
+		b.probeInFlight = false
+		b.openedAt = time.Now()
+		s.transition(tag, b, BreakerOpen, fmt.Sprintf("探测任务 %d 失败: %v", taskID, err))
+	case b.state == BreakerClosed && b.consecutiveFails >= s.threshold:
+		b.openedAt = time.Now()
+		s.transition(tag, b, BreakerOpen, fmt.Sprintf("连续失败 %d 次", b.consecutiveFails))
+	}
+}
+
+// transitionsSince 返回 since 之后发生的状态变化
+func (s *breakerSet) transitionsSince(since time.Time) []BreakerTransition {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var transitions []BreakerTransition
+	for _, t := range s.transitions {
+		if !t.Time.Before(since) {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions
+}
+
+// BreakerStatuses 返回所有熔断器的当前状态，按标签排序
+func (ts *TaskScheduler) BreakerStatuses() []BreakerStatus {
+	s := ts.breakers
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]BreakerStatus, 0, len(s.breakers))
+	for tag, b := range s.breakers {
+		statuses = append(statuses, BreakerStatus{
+			Tag:              tag,
+			State:            b.state,
+			ConsecutiveFails: b.consecutiveFails,
+			OpenedAt:         b.openedAt,
+			Rejected:         b.rejected,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Tag < statuses[j].Tag
+	})
+	return statuses
+}
+
+// printBreakerTransitions 打印一次执行期间的熔断器状态变化
+func printBreakerTransitions(transitions []BreakerTransition) {
+	if len(transitions) == 0 {
+		return
+	}
+	fmt.Printf("\n🔌 熔断器状态变化:\n")
+	for _, t := range transitions {
+		fmt.Printf("   %s [%s] %s -> %s (%s)\n",
+			t.Time.Format("15:04:05.000"), t.Tag, t.From, t.To, t.Reason)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errProbe = errors.New("下游不可用")
+
+// openBreaker 连续失败到阈值后熔断器打开
+func openBreaker(t *testing.T, s *breakerSet, tag string) {
+	t.Helper()
+	for id := 1; id <= s.threshold; id++ {
+		if !s.allow(tag, id) {
+			t.Fatalf("关闭状态下任务 %d 被拒绝", id)
+		}
+		s.record(tag, id, errProbe)
+	}
+	if state := s.get(tag).state; state != BreakerOpen {
+		t.Fatalf("连续失败后状态 = %s, 期望 open", state)
+	}
+}
+
+func TestBreakerHalfOpenClosesOnlyOnProbeSuccess(t *testing.T) {
+	s := newBreakerSet(2, 10*time.Millisecond)
+	openBreaker(t, s, "db")
+	time.Sleep(15 * time.Millisecond)
+
+	const probeID = 10
+	if !s.allow("db", probeID) {
+		t.Fatal("冷却结束后应放行探测任务")
+	}
+	if s.allow("db", 11) {
+		t.Fatal("半开状态下只应放行一个探测任务")
+	}
+
+	// 打开前已在运行的任务此时成功，不能关闭熔断器
+	s.record("db", 3, nil)
+	if state := s.get("db").state; state != BreakerHalfOpen {
+		t.Fatalf("非探测任务成功后状态 = %s, 期望 half-open", state)
+	}
+	// 非探测任务失败也不能重新打开
+	s.record("db", 4, errProbe)
+	if state := s.get("db").state; state != BreakerHalfOpen {
+		t.Fatalf("非探测任务失败后状态 = %s, 期望 half-open", state)
+	}
+
+	s.record("db", probeID, nil)
+	if state := s.get("db").state; state != BreakerClosed {
+		t.Fatalf("探测任务成功后状态 = %s, 期望 closed", state)
+	}
+}
+
+func TestBreakerHalfOpenReopensOnProbeFailure(t *testing.T) {
+	s := newBreakerSet(1, 10*time.Millisecond)
+	openBreaker(t, s, "api")
+	time.Sleep(15 * time.Millisecond)
+
+	if !s.allow("api", 5) {
+		t.Fatal("冷却结束后应放行探测任务")
+	}
+	s.record("api", 5, errProbe)
+	if state := s.get("api").state; state != BreakerOpen {
+		t.Fatalf("探测任务失败后状态 = %s, 期望 open", state)
+	}
+	if s.allow("api", 6) {
+		t.Fatal("重新打开后冷却期内应拒绝任务")
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// resultView TaskResult 的 JSON 表示，error 和耗时转换为可读字符串
+type resultView struct {
+	RunID       int         `json:"runId"`
+	TaskID      int         `json:"taskId"`
+	TaskName    string      `json:"taskName"`
+	Tenant      string      `json:"tenant"`
+	Tag         string      `json:"tag,omitempty"`
+	ParentID    int         `json:"parentId,omitempty"`
+	ChildIDs    []int       `json:"childIds,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
+	ExecuteTime string      `json:"executeTime"`
+	QueueTime   string      `json:"queueTime"`
+	PausedTime  string      `json:"pausedTime"`
+	Resources   Resources   `json:"resources"`
+	Counters    interface{} `json:"counters,omitempty"`
+	Stalled     bool        `json:"stalled"`
+	Stuck       bool        `json:"stuck"`
+	StuckDumpID int         `json:"stuckDumpId,omitempty"`
+	Error       string      `json:"error,omitempty"`
+}
+
+func newResultView(r TaskResult) resultView {
+	view := resultView{
+		RunID:       r.RunID,
+		TaskID:      r.TaskID,
+		TaskName:    r.TaskName,
+		Tenant:      r.Tenant,
+		Tag:         r.Tag,
+		ParentID:    r.ParentID,
+		ChildIDs:    r.ChildIDs,
+		Result:      r.Result,
+		ExecuteTime: r.ExecuteTime.String(),
+		QueueTime:   r.QueueTime.String(),
+		PausedTime:  r.PausedTime.String(),
+		Resources:   r.Resources,
+		Stalled:     r.Stalled,
+		Stuck:       r.Stuck,
+		StuckDumpID: r.StuckDumpID,
+	}
+	if len(r.Counters) > 0 {
+		view.Counters = r.Counters
+	}
+	if r.Error != nil {
+		view.Error = r.Error.Error()
+	}
+	return view
+}
+
+// runSummary Run 的 JSON 摘要
+type runSummary struct {
+	ID        int       `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Duration  string    `json:"duration"`
+	Tasks     int       `json:"tasks"`
+	Failed    int       `json:"failed"`
+	Stuck     int       `json:"stuck"`
+}
+
+func newRunSummary(run *Run) runSummary {
+	summary := runSummary{
+		ID:        run.ID,
+		StartTime: run.StartTime,
+		EndTime:   run.EndTime,
+		Duration:  run.Duration().String(),
+		Tasks:     len(run.Results),
+	}
+	for _, result := range run.Results {
+		if result.Error != nil {
+			summary.Failed++
+		}
+		if result.Stuck {
+			summary.Stuck++
+		}
+	}
+	return summary
+}
+
+// StartDashboard 在 addr 上启动可选的 HTTP 监控面板，返回的 Server 可用于关闭服务
+//
+//	GET /             任务状态页面（运行中、排队中、已完成）
+//	GET /api/tasks    所有任务的状态快照
+//	GET /api/results  已完成任务的执行结果
+//	GET /api/events   任务生命周期事件的 SSE 流
+//	GET /api/progress 整批任务进度及各任务上报的计数器、心跳
+//	GET /api/runs     历史执行记录摘要
+//	GET /api/runs/{id}/dumps 该次执行中卡住任务的协程调用栈
+//	GET /api/tenants  各租户的执行统计
+//	GET /api/breakers 各标签熔断器状态
+//	GET /api/status   调度器状态（是否暂停、各状态任务数）
+//	POST /api/pause   暂停派发新任务
+//	POST /api/resume  恢复派发任务
+//	POST /api/tasks/{id}/cancel 取消任务及其子任务
+func (ts *TaskScheduler) StartDashboard(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("启动监控面板失败: %w", err)
+	}
+
+	server := &http.Server{Handler: ts.dashboardHandler()}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("监控面板异常退出: %v\n", err)
+		}
+	}()
+	fmt.Printf("监控面板已启动: http://%s/\n", listener.Addr())
+	return server, nil
+}
+
+func (ts *TaskScheduler) dashboardHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, dashboardHTML)
+	})
+	mux.HandleFunc("GET /api/tasks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ts.TaskStatuses())
+	})
+	mux.HandleFunc("GET /api/results", func(w http.ResponseWriter, r *http.Request) {
+		results := ts.Results()
+		views := make([]resultView, 0, len(results))
+		for _, result := range results {
+			views = append(views, newResultView(result))
+		}
+		writeJSON(w, views)
+	})
+	mux.HandleFunc("GET /api/events", ts.serveEvents)
+	mux.HandleFunc("GET /api/progress", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ts.Progress())
+	})
+	mux.HandleFunc("GET /api/runs", func(w http.ResponseWriter, r *http.Request) {
+		runs := ts.Runs()
+		summaries := make([]runSummary, 0, len(runs))
+		for _, run := range runs {
+			summaries = append(summaries, newRunSummary(run))
+		}
+		writeJSON(w, summaries)
+	})
+	mux.HandleFunc("GET /api/runs/{id}/dumps", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "无效的执行ID", http.StatusBadRequest)
+			return
+		}
+		for _, run := range ts.Runs() {
+			if run.ID == id {
+				writeJSON(w, run.GoroutineDumps)
+				return
+			}
+		}
+		http.Error(w, "执行记录不存在", http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /api/tenants", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ts.TenantStats())
+	})
+	mux.HandleFunc("GET /api/breakers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ts.BreakerStatuses())
+	})
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ts.Status())
+	})
+	mux.HandleFunc("POST /api/pause", func(w http.ResponseWriter, r *http.Request) {
+		ts.Pause()
+		writeJSON(w, ts.Status())
+	})
+	mux.HandleFunc("POST /api/resume", func(w http.ResponseWriter, r *http.Request) {
+		ts.Resume()
+		writeJSON(w, ts.Status())
+	})
+	mux.HandleFunc("POST /api/tasks/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "无效的任务ID", http.StatusBadRequest)
+			return
+		}
+		if !ts.CancelTask(id) {
+			http.Error(w, "任务不存在或当前没有正在进行的执行", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"taskId": id, "canceled": true})
+	})
+	return mux
+}
+
+// serveEvents 以 Server-Sent Events 推送任务生命周期事件，直到客户端断开
+func (ts *TaskScheduler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "不支持流式响应", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	events, unsubscribe := ts.Subscribe()
+	defer unsubscribe()
+
+	// 定期发送注释行保持连接，便于代理和浏览器检测断线
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.State, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeJSON 先完整编码到缓冲区再写出，编码失败时只返回错误响应，不会在部分 JSON 之后追加错误信息
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+const dashboardHTML = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>任务调度器监控面板</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+h2 { margin-top: 1.5em; }
+table { border-collapse: collapse; min-width: 40em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.failed { color: #c00; }
+.succeeded { color: #080; }
+</style>
+</head>
+<body>
+<h1>任务调度器监控面板</h1>
+<div id="summary"></div>
+<div id="progress"></div>
+<p><button onclick="control('pause')">暂停</button> <button onclick="control('resume')">恢复</button> <span id="status"></span></p>
+<h2>运行中</h2><table id="running"></table>
+<h2>排队中</h2><table id="queued"></table>
+<h2>已完成</h2><table id="finished"></table>
+<script>
+function fmtDuration(ns) {
+  if (!ns) return "-";
+  return ns >= 1e9 ? (ns / 1e9).toFixed(2) + "s" : (ns / 1e6).toFixed(1) + "ms";
+}
+function cell(tag, text) {
+  var el = document.createElement(tag);
+  el.textContent = text;
+  return el;
+}
+function render(id, rows) {
+  // 任务名称和错误信息来自任务代码，只能以文本方式写入，避免被当作 HTML 执行
+  var table = document.getElementById(id);
+  table.replaceChildren();
+  var header = document.createElement("tr");
+  ["ID", "名称", "租户", "状态", "ExecuteTime", "错误"].forEach(function (name) {
+    header.appendChild(cell("th", name));
+  });
+  table.appendChild(header);
+  rows.forEach(function (t) {
+    var elapsed = t.executeTime;
+    if (t.state === "running" && t.startedAt) {
+      elapsed = (Date.now() - Date.parse(t.startedAt)) * 1e6;
+    }
+    var row = document.createElement("tr");
+    row.className = t.state;
+    [t.taskId, t.taskName, t.tenant, t.state, fmtDuration(elapsed), t.error || ""].forEach(function (value) {
+      row.appendChild(cell("td", String(value)));
+    });
+    table.appendChild(row);
+  });
+}
+function control(action) {
+  fetch("/api/" + action, { method: "POST" }).then(scheduleRefresh);
+}
+function refresh() {
+  fetch("/api/status").then(function (r) { return r.json(); }).then(function (s) {
+    document.getElementById("status").textContent = s.paused ?
+      "已暂停（自 " + new Date(s.pausedSince).toLocaleTimeString() + "）" : "运行中";
+  });
+  fetch("/api/progress").then(function (r) { return r.json(); }).then(function (p) {
+    var text = "批次进度: " + p.percent.toFixed(1) + "%";
+    if (p.stalled > 0) text += "（" + p.stalled + " 个任务疑似卡住）";
+    document.getElementById("progress").textContent = text;
+  });
+  fetch("/api/tasks").then(function (r) { return r.json(); }).then(function (tasks) {
+    var running = [], queued = [], finished = [];
+    tasks.forEach(function (t) {
+      if (t.state === "running") running.push(t);
+      else if (t.state === "queued") queued.push(t);
+      else finished.push(t);
+    });
+    render("running", running);
+    render("queued", queued);
+    render("finished", finished);
+    document.getElementById("summary").textContent =
+      "运行中 " + running.length + " / 排队中 " + queued.length + " / 已完成 " + finished.length;
+  });
+}
+// 一批任务会在短时间内产生大量事件，合并为每 200ms 最多刷新一次
+var refreshPending = false;
+function scheduleRefresh() {
+  if (refreshPending) return;
+  refreshPending = true;
+  setTimeout(function () {
+    refreshPending = false;
+    refresh();
+  }, 200);
+}
+var source = new EventSource("/api/events");
+source.onmessage = scheduleRefresh;
+["queued", "running", "succeeded", "failed"].forEach(function (name) {
+  source.addEventListener(name, scheduleRefresh);
+});
+setInterval(refresh, 1000);
+refresh();
+</script>
+</body>
+</html>
+`
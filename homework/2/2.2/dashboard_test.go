@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// 编码失败时只返回错误响应，不会先写出部分 JSON
+func TestWriteJSONEncodeError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeJSON(rec, []interface{}{map[string]int{"ok": 1}, math.NaN()})
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("状态码 = %d, 期望 %d", rec.Code, http.StatusInternalServerError)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "ok") || !strings.Contains(body, "NaN") {
+		t.Errorf("响应体 = %q, 期望只包含编码错误", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, 错误响应不应声明为 JSON", ct)
+	}
+
+	rec = httptest.NewRecorder()
+	writeJSON(rec, map[string]int{"ok": 1})
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\n  \"ok\": 1\n}\n" {
+		t.Errorf("正常响应 = %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaskState 任务生命周期状态
+type TaskState string
+
+const (
+	TaskQueued    TaskState = "queued"
+	TaskRunning   TaskState = "running"
+	TaskSucceeded TaskState = "succeeded"
+	TaskFailed    TaskState = "failed"
+)
+
+// Finished 判断任务是否已经结束
+func (s TaskState) Finished() bool {
+	return s == TaskSucceeded || s == TaskFailed
+}
+
+// TaskEvent 任务生命周期事件
+type TaskEvent struct {
+	Time        time.Time     `json:"time"`
+	TaskID      int           `json:"taskId"`
+	TaskName    string        `json:"taskName"`
+	State       TaskState     `json:"state"`
+	ExecuteTime time.Duration `json:"executeTime,omitempty"`
+	Message     string        `json:"message,omitempty"`
+}
+
+// TaskStatus 任务当前状态快照
+type TaskStatus struct {
+	TaskID      int           `json:"taskId"`
+	TaskName    string        `json:"taskName"`
+	Tenant      string        `json:"tenant"`
+	State       TaskState     `json:"state"`
+	Resources   Resources     `json:"resources"`
+	QueuedAt    time.Time     `json:"queuedAt"`
+	StartedAt   time.Time     `json:"startedAt,omitzero"`
+	FinishedAt  time.Time     `json:"finishedAt,omitzero"`
+	ExecuteTime time.Duration `json:"executeTime"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// eventBus 将任务事件广播给所有订阅者；订阅者消费过慢时丢弃事件，不阻塞调度
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan TaskEvent]struct{}
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: make(map[chan TaskEvent]struct{})}
+}
+
+func (b *eventBus) subscribe(buffer int) chan TaskEvent {
+	ch := make(chan TaskEvent, buffer)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *eventBus) unsubscribe(ch chan TaskEvent) {
+	b.mu.Lock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+func (b *eventBus) publish(event TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe 订阅任务生命周期事件，返回事件通道和取消订阅函数
+func (ts *TaskScheduler) Subscribe() (<-chan TaskEvent, func()) {
+	ch := ts.events.subscribe(64)
+	return ch, func() { ts.events.unsubscribe(ch) }
+}
+
+// setTaskState 更新任务状态并发布对应的生命周期事件
+func (ts *TaskScheduler) setTaskState(t Task, state TaskState, executeTime time.Duration, err error) {
+	now := time.Now()
+
+	ts.statusMu.Lock()
+	status, ok := ts.statuses[t.ID]
+	if !ok {
+		status = &TaskStatus{TaskID: t.ID, TaskName: t.Name, Tenant: t.Tenant, Resources: t.Resources}
+		ts.statuses[t.ID] = status
+	}
+	status.State = state
+	switch state {
+	case TaskQueued:
+		status.QueuedAt = now
+		status.StartedAt = time.Time{}
+		status.FinishedAt = time.Time{}
+		status.ExecuteTime = 0
+		status.Error = ""
+	case TaskRunning:
+		status.StartedAt = now
+	default:
+		status.FinishedAt = now
+		status.ExecuteTime = executeTime
+	}
+	event := TaskEvent{
+		Time:        now,
+		TaskID:      t.ID,
+		TaskName:    t.Name,
+		State:       state,
+		ExecuteTime: executeTime,
+	}
+	if err != nil {
+		status.Error = err.Error()
+		event.Message = err.Error()
+	}
+	ts.statusMu.Unlock()
+
+	ts.events.publish(event)
+}
+
+// TaskStatuses 返回所有任务当前状态的快照，按任务ID排序
+func (ts *TaskScheduler) TaskStatuses() []TaskStatus {
+	ts.statusMu.Lock()
+	statuses := make([]TaskStatus, 0, len(ts.statuses))
+	for _, status := range ts.statuses {
+		statuses = append(statuses, *status)
+	}
+	ts.statusMu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].TaskID < statuses[j].TaskID
+	})
+	return statuses
+}
+
+// Results 返回已收集的任务执行结果快照
+func (ts *TaskScheduler) Results() []TaskResult {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	results := make([]TaskResult, len(ts.results))
+	copy(results, ts.results)
+	return results
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTaskCanceled 任务（或其祖先任务）被取消
+var ErrTaskCanceled = errors.New("任务已取消")
+
+// execution 一次 ExecuteTasks 的运行期状态
+// 顶层任务和运行中任务提交的子任务都经由它入队、派发和汇总结果
+type execution struct {
+	ts      *TaskScheduler
+	run     *Run
+	queue   *fairQueue
+	backend dispatchBackend
+	spans   *spanRecorder
+	results chan TaskResult
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	cancels map[int]context.CancelFunc
+	waiters map[int]chan TaskResult
+	nextID  int
+}
+
+func (ts *TaskScheduler) newExecution(run *Run) *execution {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &execution{
+		ts:      ts,
+		run:     run,
+		queue:   newFairQueue(ts.tenantPolicies),
+		backend: newDispatchBackend(ts.backendKind, ts.backendWorkers),
+		spans:   newSpanRecorder(),
+		results: make(chan TaskResult, len(ts.tasks)),
+		ctx:     ctx,
+		cancel:  cancel,
+		cancels: make(map[int]context.CancelFunc),
+		waiters: make(map[int]chan TaskResult),
+	}
+	for _, task := range ts.tasks {
+		e.nextID = max(e.nextID, task.ID)
+	}
+	return e
+}
+
+// enqueue 为任务派生上下文并放入公平队列；资源需求超过容量的任务直接失败
+func (e *execution) enqueue(t Task, parentCtx context.Context) {
+	ctx, cancel := context.WithCancel(parentCtx)
+	t.ctx = ctx
+	t.queuedAt = time.Now()
+	t.pausedBefore = e.ts.pause.elapsed()
+	t.spanID = newSpanID()
+
+	e.mu.Lock()
+	e.cancels[t.ID] = cancel
+	e.mu.Unlock()
+
+	e.ts.setTaskState(t, TaskQueued, 0, nil)
+	if err := e.ts.resources.fits(t.Resources); err != nil {
+		fmt.Printf("任务 [%s] 被拒绝: %v\n", t.Name, err)
+		e.reject(t, err)
+		return
+	}
+	e.queue.push(t)
+}
+
+// dispatch 调度循环：依次取出任务、等待准入后交给执行后端，直到队列清空且没有运行中的任务
+func (e *execution) dispatch() {
+	ts := e.ts
+	for {
+		task, ok := e.queue.next()
+		if !ok {
+			return
+		}
+		if err := task.ctx.Err(); err != nil {
+			e.rejectQueued(task, fmt.Errorf("%w: %v", ErrTaskCanceled, err))
+			continue
+		}
+
+		ts.admit(task.Resources)
+		// 准入后再检查取消和熔断器，确保看到的是等待期间的最新状态
+		if err := task.ctx.Err(); err != nil {
+			ts.resources.release(task.Resources)
+			e.rejectQueued(task, fmt.Errorf("%w: %v", ErrTaskCanceled, err))
+			continue
+		}
+		if !ts.breakers.allow(task.Tag, task.ID) {
+			ts.resources.release(task.Resources)
+			err := fmt.Errorf("%w: 标签 %s", ErrCircuitOpen, task.Tag)
+			fmt.Printf("任务 [%s] 快速失败: %v\n", task.Name, err)
+			e.rejectQueued(task, err)
+			continue
+		}
+		admittedAt := time.Now()
+		queueTime := admittedAt.Sub(task.queuedAt)
+		pausedTime := ts.pause.elapsed() - task.pausedBefore
+
+		t := task
+		lease := &taskLease{e: e, t: t, held: true}
+		e.backend.submit(func() {
+			defer lease.end()
+
+			taskResult := ts.runTask(e, t, lease)
+			ts.breakers.record(t.Tag, t.ID, taskResult.Error)
+			taskResult.QueueTime = queueTime
+			taskResult.PausedTime = pausedTime
+			e.spans.recordTask(t, t.queuedAt, admittedAt, time.Now(), taskResult)
+			e.complete(taskResult)
+		})
+	}
+}
+
+// taskLease 已派发任务占用的资源和租户并发配额。任务等待子任务期间暂时让出，
+// 任务结束（包括被看门狗放弃）时由调度循环统一归还；结束后不再重新占用，保证每份资源只归还一次
+type taskLease struct {
+	e *execution
+	t Task
+
+	mu sync.Mutex
+	// held 资源当前由任务持有；ended 调度循环已结束该任务
+	held  bool
+	ended bool
+}
+
+// suspend 让出资源和租户配额，返回是否确实让出；任务已结束或已让出时什么也不做
+func (l *taskLease) suspend() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ended || !l.held {
+		return false
+	}
+	l.held = false
+	l.e.ts.resources.release(l.t.Resources)
+	l.e.queue.suspend(l.t.Tenant)
+	return true
+}
+
+// resume 重新占用 suspend 让出的资源，可能阻塞等待资源空出；
+// 等待期间任务被结束时立即归还刚获得的资源
+func (l *taskLease) resume() {
+	l.mu.Lock()
+	ended := l.ended
+	l.mu.Unlock()
+	if ended {
+		return
+	}
+
+	l.e.ts.resources.acquire(l.t.Resources)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ended {
+		l.e.ts.resources.release(l.t.Resources)
+		return
+	}
+	l.held = true
+	l.e.queue.resume(l.t.Tenant)
+}
+
+// end 任务结束时归还仍持有的资源和租户配额，只有调度循环调用
+func (l *taskLease) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ended = true
+	if l.held {
+		l.held = false
+		l.e.ts.resources.release(l.t.Resources)
+	} else {
+		// 等待子任务时已让出租户配额，先补回再与正常结束一样归还
+		l.e.queue.resume(l.t.Tenant)
+	}
+	l.e.queue.done(l.t.Tenant)
+}
+
+// reject 生成未执行即失败的任务结果
+func (e *execution) reject(t Task, err error) {
+	rejected := TaskResult{
+		TaskID:    t.ID,
+		TaskName:  t.Name,
+		Tenant:    t.Tenant,
+		Tag:       t.Tag,
+		ParentID:  t.ParentID,
+		Resources: t.Resources,
+		Error:     err,
+	}
+	e.spans.recordTask(t, t.queuedAt, time.Time{}, time.Now(), rejected)
+	e.ts.setTaskState(t, TaskFailed, 0, err)
+	e.complete(rejected)
+}
+
+// rejectQueued 拒绝已从队列取出的任务，并归还其租户配额
+func (e *execution) rejectQueued(t Task, err error) {
+	e.reject(t, err)
+	e.queue.done(t.Tenant)
+}
+
+// complete 汇总任务结果，并通知等待该子任务的父任务
+func (e *execution) complete(result TaskResult) {
+	result.RunID = e.run.ID
+	e.mu.Lock()
+	waiter, ok := e.waiters[result.TaskID]
+	delete(e.waiters, result.TaskID)
+	e.mu.Unlock()
+	if ok {
+		waiter <- result
+	}
+	e.results <- result
+}
+
+// collect 在后台收集任务结果，返回的通道在 results 关闭且收集完毕后关闭
+func (e *execution) collect() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range e.results {
+			e.ts.mu.Lock()
+			e.ts.results = append(e.ts.results, result)
+			e.ts.mu.Unlock()
+		}
+	}()
+	return done
+}
+
+// cancelTask 取消任务的上下文，其所有子孙任务随之取消
+func (e *execution) cancelTask(id int) bool {
+	e.mu.Lock()
+	cancel, ok := e.cancels[id]
+	e.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// nextTaskID 为子任务分配不与已有任务冲突的ID
+func (e *execution) nextTaskID() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.nextID++
+	return e.nextID
+}
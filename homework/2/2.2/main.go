@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -12,155 +19,370 @@ import (
 考察点：协程原理、并发任务调度。 */
 
 // 题目1：协程打印奇偶数
+// 奇数协程和偶数协程通过 OrderedPrinter 严格交替执行，输出顺序不依赖各自的休眠时间
 func printOddEvenNumbers() {
 	fmt.Println("=== 题目1：协程打印奇偶数 ===")
-	var wg sync.WaitGroup
-	wg.Add(2)
+	NewOrderedPrinter(2, StrategyChannel).Run(10, func(worker, value int) {
+		if worker == 0 {
+			fmt.Printf("奇数: %d\n", value)
+		} else {
+			fmt.Printf("偶数: %d\n", value)
+		}
+	})
+	fmt.Println("所有协程执行完成")
+	fmt.Println()
+}
 
-	// 协程1：打印奇数 (1, 3, 5, 7, 9)
-	go func() {
-		defer wg.Done()
-		fmt.Println("奇数协程开始执行...")
-		for i := 1; i <= 10; i++ {
-			if i%2 != 0 {
-				fmt.Printf("奇数: %d\n", i)
-				time.Sleep(100 * time.Millisecond) // 模拟处理时间
-			}
-		}
-		fmt.Println("奇数协程执行完成")
-	}()
+// Task 表示一个任务
+type Task struct {
+	ID        int
+	Name      string
+	Func      func() interface{}
+	Resources Resources
+	Tenant    string
+	Tag       string
 
-	// 协程2：打印偶数 (2, 4, 6, 8, 10)
-	go func() {
-		defer wg.Done()
-		fmt.Println("偶数协程开始执行...")
-		for i := 1; i <= 10; i++ {
-			if i%2 == 0 {
-				fmt.Printf("偶数: %d\n", i)
-				time.Sleep(120 * time.Millisecond) // 模拟处理时间
-			}
-		}
-		fmt.Println("偶数协程执行完成")
-	}()
+	// HandleFunc 需要上报进度的任务使用，与 Func 二选一
+	HandleFunc func(h *TaskHandle) interface{}
+	// ParentID 通过 TaskHandle.Spawn 提交的子任务记录父任务ID，顶层任务为 0
+	ParentID int
 
-	// 等待所有协程完成
-	wg.Wait()
-	fmt.Println("所有协程执行完成\n")
+	ctx          context.Context
+	queuedAt     time.Time
+	pausedBefore time.Duration
+	spanID       string
+	parentSpanID string
 }
 
-// Task 表示一个任务
-type Task struct {
-	ID   int
-	Name string
-	Func func() interface{}
+// TaskOption 添加任务时的可选配置
+type TaskOption func(*Task)
+
+// WithResources 声明任务所需的 CPU 槽位和内存（MB），调度器据此进行准入控制
+func WithResources(cpu, memoryMB int) TaskOption {
+	return func(t *Task) {
+		t.Resources = Resources{CPU: cpu, MemoryMB: memoryMB}
+	}
 }
 
 // TaskResult 表示任务执行结果
 type TaskResult struct {
+	RunID       int
 	TaskID      int
 	TaskName    string
+	Tenant      string
+	Tag         string
+	ParentID    int
+	ChildIDs    []int
 	Result      interface{}
+	StartTime   time.Time
 	ExecuteTime time.Duration
+	QueueTime   time.Duration
+	PausedTime  time.Duration
+	Resources   Resources
+	Counters    map[string]int64
+	Stalled     bool
+	Stuck       bool
+	// StuckDumpID 任务被判定为卡住时抓取的调用栈编号，对应 Run.GoroutineDumps 中的 ID，0 表示没有
+	StuckDumpID int
 	Error       error
 }
 
 // TaskScheduler 任务调度器
 type TaskScheduler struct {
-	tasks   []Task
-	results []TaskResult
-	mu      sync.Mutex
+	tasks     []Task
+	results   []TaskResult
+	resources *resourcePool
+	pause     *pauseGate
+	mu        sync.Mutex
+
+	events   *eventBus
+	statuses map[int]*TaskStatus
+	statusMu sync.Mutex
+
+	handles        map[int]*TaskHandle
+	handleMu       sync.Mutex
+	stallThreshold time.Duration
+
+	tenantPolicies map[string]TenantPolicy
+
+	watchdog *watchdog
+	breakers *breakerSet
+
+	backendKind    BackendKind
+	backendWorkers int
+
+	runs         []*Run
+	nextRunID    int
+	historyLimit int
+
+	// current 正在进行的执行，用于取消任务
+	current *execution
+}
+
+// SchedulerOption 创建调度器时的可选配置
+type SchedulerOption func(*TaskScheduler)
+
+// WithCapacity 设置调度器可提供的 CPU 槽位和内存（MB）容量，0 表示不限制
+func WithCapacity(cpu, memoryMB int) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.resources = newResourcePool(Resources{CPU: cpu, MemoryMB: memoryMB})
+	}
+}
+
+// WithStallThreshold 设置心跳超时阈值，使用 TaskHandle 的任务超过该时长未上报心跳即被标记为卡住
+func WithStallThreshold(d time.Duration) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.stallThreshold = d
+	}
 }
 
 // NewTaskScheduler 创建新的任务调度器
-func NewTaskScheduler() *TaskScheduler {
-	return &TaskScheduler{
-		tasks:   make([]Task, 0),
-		results: make([]TaskResult, 0),
+func NewTaskScheduler(opts ...SchedulerOption) *TaskScheduler {
+	ts := &TaskScheduler{
+		tasks:     make([]Task, 0),
+		results:   make([]TaskResult, 0),
+		resources: newResourcePool(Resources{}),
+		pause:     newPauseGate(),
+		events:    newEventBus(),
+		statuses:  make(map[int]*TaskStatus),
+		handles:   make(map[int]*TaskHandle),
+
+		tenantPolicies: make(map[string]TenantPolicy),
+		watchdog:       newWatchdog(),
+		historyLimit:   defaultHistoryLimit,
 	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	return ts
 }
 
 // AddTask 添加任务
-func (ts *TaskScheduler) AddTask(id int, name string, taskFunc func() interface{}) {
+func (ts *TaskScheduler) AddTask(id int, name string, taskFunc func() interface{}, opts ...TaskOption) {
 	task := Task{
-		ID:   id,
-		Name: name,
-		Func: taskFunc,
+		ID:        id,
+		Name:      name,
+		Func:      taskFunc,
+		Resources: defaultTaskResources,
+		Tenant:    defaultTenant,
+	}
+	for _, opt := range opts {
+		opt(&task)
 	}
 	ts.tasks = append(ts.tasks, task)
 }
 
-// ExecuteTasks 并发执行所有任务
-func (ts *TaskScheduler) ExecuteTasks() {
+// AddTaskWithHandle 添加可通过 TaskHandle 上报进度和心跳的任务
+func (ts *TaskScheduler) AddTaskWithHandle(id int, name string, taskFunc func(h *TaskHandle) interface{}, opts ...TaskOption) {
+	ts.AddTask(id, name, nil, opts...)
+	ts.tasks[len(ts.tasks)-1].HandleFunc = taskFunc
+}
+
+// ExecuteTasks 并发执行所有任务，每次调用都是一次独立的 Run，结果不会与之前的执行混在一起
+// 任务按租户公平轮转（同一租户内按添加顺序）依次准入：当剩余资源不足以满足选中的任务时，
+// 调度循环会阻塞等待运行中的任务释放资源；
+// 调度器暂停期间不会派发新任务，暂停时长计入任务的排队时间。
+// 运行中的任务通过 TaskHandle.Spawn 提交的子任务同样经过公平队列和准入控制，并计入本次 Run
+func (ts *TaskScheduler) ExecuteTasks() *Run {
 	fmt.Println("=== 题目2：任务调度器并发执行 ===")
-	var wg sync.WaitGroup
-	resultChan := make(chan TaskResult, len(ts.tasks))
+	batchStart := time.Now()
+	ts.mu.Lock()
+	ts.nextRunID++
+	run := &Run{ID: ts.nextRunID, StartTime: batchStart}
+	ts.results = make([]TaskResult, 0, len(ts.tasks))
+	e := ts.newExecution(run)
+	ts.current = e
+	ts.mu.Unlock()
+	defer e.cancel()
+	ts.statusMu.Lock()
+	ts.statuses = make(map[int]*TaskStatus)
+	ts.statusMu.Unlock()
+	fmt.Printf("Run #%d 开始\n", run.ID)
+	ts.watchdog.beginRun(run.ID)
 
-	// 启动协程执行每个任务
+	ts.resources.reset(batchStart)
+
+	monitorDone := make(chan struct{})
+	defer close(monitorDone)
+	go ts.monitorStalls(monitorDone)
+	go ts.runWatchdog(monitorDone)
+
+	// 子任务的结果可能多于顶层任务数，因此在派发的同时收集结果
+	collected := e.collect()
 	for _, task := range ts.tasks {
-		wg.Add(1)
-		go func(t Task) {
-			defer wg.Done()
-			fmt.Printf("任务 [%s] 开始执行...\n", t.Name)
-			
-			startTime := time.Now()
-			var result interface{}
-			var err error
-			
-			// 执行任务并捕获可能的panic
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						err = fmt.Errorf("任务执行panic: %v", r)
-					}
-				}()
-				result = t.Func()
-			}()
-			
-			executeTime := time.Since(startTime)
-			
-			taskResult := TaskResult{
-				TaskID:      t.ID,
-				TaskName:    t.Name,
-				Result:      result,
-				ExecuteTime: executeTime,
-				Error:       err,
-			}
-			
-			resultChan <- taskResult
-			fmt.Printf("任务 [%s] 执行完成，耗时: %v\n", t.Name, executeTime)
-		}(task)
-	}
-
-	// 等待所有任务完成
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+		e.enqueue(task, e.ctx)
+	}
+
+	// 依次准入并交给执行后端，直到所有任务（包括子任务）都已结束
+	e.dispatch()
+	e.backend.shutdown()
+	close(e.results)
+	<-collected
+
+	ts.mu.Lock()
+	ts.current = nil
+	ts.mu.Unlock()
+
+	run.EndTime = time.Now()
+	run.Results = ts.Results()
+	run.Spans = e.spans.sorted()
+	run.BreakerTransitions = ts.breakers.transitionsSince(batchStart)
+	run.GoroutineDumps = ts.watchdog.runDumps()
+	ts.recordRun(run)
+	fmt.Printf("\n所有任务执行完成 (Run #%d, 耗时 %v)\n", run.ID, run.Duration().Round(time.Millisecond))
+	return run
+}
+
+// admit 等待调度器处于运行状态并为任务获取资源
+// 若在等待资源期间调度器被暂停，则先归还资源，待恢复后重新申请
+func (ts *TaskScheduler) admit(req Resources) {
+	for {
+		ts.pause.wait()
+		ts.resources.acquire(req)
+		if !ts.pause.isPaused() {
+			return
+		}
+		ts.resources.release(req)
+	}
+}
+
+// runTask 执行单个任务，统计耗时并捕获可能的panic
+func (ts *TaskScheduler) runTask(e *execution, t Task, lease *taskLease) TaskResult {
+	fmt.Printf("任务 [%s] 开始执行... (%s)\n", t.Name, t.Resources)
+	ts.setTaskState(t, TaskRunning, 0, nil)
 
-	// 收集结果
-	for result := range resultChan {
-		ts.mu.Lock()
-		ts.results = append(ts.results, result)
-		ts.mu.Unlock()
+	var handle *TaskHandle
+	if t.HandleFunc != nil {
+		handle = newTaskHandle(e, t, lease)
+		ts.handleMu.Lock()
+		ts.handles[t.ID] = handle
+		ts.handleMu.Unlock()
 	}
 
-	fmt.Println("\n所有任务执行完成")
+	rt := ts.watchdog.track(t, handle)
+	startTime := time.Now()
+	var result interface{}
+	var err error
+
+	if ts.watchdog.abandon {
+		// 在独立协程中执行，看门狗判定卡住后可以不再等待
+		outcome := make(chan taskOutcome, 1)
+		go func() {
+			r, e := invokeTask(t, handle)
+			outcome <- taskOutcome{result: r, err: e}
+		}()
+		select {
+		case o := <-outcome:
+			result, err = o.result, o.err
+		case <-rt.abandon:
+			err = fmt.Errorf("%w: 超过 %v", ErrTaskAbandoned, ts.watchdog.threshold)
+		}
+	} else {
+		result, err = invokeTask(t, handle)
+	}
+
+	executeTime := time.Since(startTime)
+	if err == nil && handle != nil {
+		err = handle.failed()
+	}
+	if err == nil && t.ctx.Err() != nil {
+		// 执行期间被取消的任务即使正常返回，其结果也不再可信
+		err = fmt.Errorf("%w: %v", ErrTaskCanceled, t.ctx.Err())
+	}
+	taskResult := TaskResult{
+		TaskID:      t.ID,
+		TaskName:    t.Name,
+		Tenant:      t.Tenant,
+		Tag:         t.Tag,
+		ParentID:    t.ParentID,
+		Result:      result,
+		StartTime:   startTime,
+		ExecuteTime: executeTime,
+		Resources:   t.Resources,
+		Error:       err,
+	}
+	taskResult.Stuck, taskResult.StuckDumpID = ts.watchdog.untrack(t)
+	if handle != nil {
+		ts.handleMu.Lock()
+		delete(ts.handles, t.ID)
+		ts.handleMu.Unlock()
+		taskResult.Counters = handle.snapshot().Counters
+		taskResult.Stalled = handle.wasStalled()
+		taskResult.ChildIDs = handle.childIDs()
+	}
+	fmt.Printf("任务 [%s] 执行完成，耗时: %v\n", t.Name, executeTime)
+	if err != nil {
+		ts.setTaskState(t, TaskFailed, executeTime, err)
+	} else {
+		ts.setTaskState(t, TaskSucceeded, executeTime, nil)
+	}
+	return taskResult
+}
+
+type taskOutcome struct {
+	result interface{}
+	err    error
+}
+
+// invokeTask 在带有任务标签的协程上下文中调用任务函数并捕获可能的panic
+// 标签会出现在看门狗抓取的协程调用栈中，便于定位卡住的任务
+func invokeTask(t Task, handle *TaskHandle) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务执行panic: %v", r)
+		}
+	}()
+	labels := pprof.Labels("task_id", strconv.Itoa(t.ID), "task", t.Name)
+	pprof.Do(t.ctx, labels, func(context.Context) {
+		if handle != nil {
+			result = t.HandleFunc(handle)
+		} else {
+			result = t.Func()
+		}
+	})
+	return result, err
 }
 
-// PrintResults 打印任务执行结果统计
+// PrintResults 打印最近一次执行的任务结果统计
 func (ts *TaskScheduler) PrintResults() {
 	fmt.Println("\n=== 任务执行结果统计 ===")
+	if run := ts.LastRun(); run != nil {
+		fmt.Printf("Run #%d: %s - %s\n", run.ID,
+			run.StartTime.Format("15:04:05.000"), run.EndTime.Format("15:04:05.000"))
+	}
 	totalTime := time.Duration(0)
+	totalQueueTime := time.Duration(0)
+	totalPausedTime := time.Duration(0)
 	successCount := 0
 	errorCount := 0
+	stalledCount := 0
+	stuckCount := 0
 
 	for _, result := range ts.results {
 		totalTime += result.ExecuteTime
-		if result.Error != nil {
+		totalQueueTime += result.QueueTime
+		totalPausedTime += result.PausedTime
+		if result.Stalled {
+			stalledCount++
+			fmt.Printf("⚠️  任务ID: %d, 名称: %s, 执行期间曾心跳超时\n", result.TaskID, result.TaskName)
+		}
+		if result.Stuck {
+			stuckCount++
+		}
+		switch {
+		case result.Stuck && result.Error != nil:
+			errorCount++
+			fmt.Printf("🧊 任务ID: %d, 名称: %s, 状态: 卡住, 耗时: %v, 调用栈快照: #%d, 错误: %v\n",
+				result.TaskID, result.TaskName, result.ExecuteTime, result.StuckDumpID, result.Error)
+		case result.Stuck:
+			successCount++
+			fmt.Printf("🧊 任务ID: %d, 名称: %s, 状态: 卡住(最终完成), 耗时: %v, 调用栈快照: #%d, 结果: %v\n",
+				result.TaskID, result.TaskName, result.ExecuteTime, result.StuckDumpID, result.Result)
+		case result.Error != nil:
 			errorCount++
 			fmt.Printf("❌ 任务ID: %d, 名称: %s, 状态: 失败, 耗时: %v, 错误: %v\n",
 				result.TaskID, result.TaskName, result.ExecuteTime, result.Error)
-		} else {
+		default:
 			successCount++
 			fmt.Printf("✅ 任务ID: %d, 名称: %s, 状态: 成功, 耗时: %v, 结果: %v\n",
 				result.TaskID, result.TaskName, result.ExecuteTime, result.Result)
@@ -171,9 +393,24 @@ func (ts *TaskScheduler) PrintResults() {
 	fmt.Printf("   总任务数: %d\n", len(ts.results))
 	fmt.Printf("   成功任务: %d\n", successCount)
 	fmt.Printf("   失败任务: %d\n", errorCount)
+	if stalledCount > 0 {
+		fmt.Printf("   心跳超时任务: %d\n", stalledCount)
+	}
+	if stuckCount > 0 {
+		dumps := 0
+		if run := ts.LastRun(); run != nil {
+			dumps = len(run.GoroutineDumps)
+		}
+		fmt.Printf("   卡住任务: %d (协程调用栈快照 %d 份)\n", stuckCount, dumps)
+	}
 	fmt.Printf("   总耗时: %v\n", totalTime)
 	if len(ts.results) > 0 {
 		fmt.Printf("   平均耗时: %v\n", totalTime/time.Duration(len(ts.results)))
+		fmt.Printf("   平均排队: %v (其中暂停 %v)\n",
+			totalQueueTime/time.Duration(len(ts.results)), totalPausedTime/time.Duration(len(ts.results)))
+	}
+	if run := ts.LastRun(); run != nil {
+		printBreakerTransitions(run.BreakerTransitions)
 	}
 }
 
@@ -189,6 +426,38 @@ func calculateSum(n int) func() interface{} {
 	}
 }
 
+// calculateSumWithProgress 与 calculateSum 相同，但通过句柄上报进度和已累加的项数
+func calculateSumWithProgress(n int) func(h *TaskHandle) interface{} {
+	return func(h *TaskHandle) interface{} {
+		sum := 0
+		for i := 1; i <= n; i++ {
+			sum += i
+			time.Sleep(10 * time.Millisecond) // 模拟计算时间
+			h.AddCounter("已累加项数", 1)
+			h.SetProgress(float64(i) * 100 / float64(n))
+		}
+		return sum
+	}
+}
+
+// simulateStalledTask 上报一次心跳后长时间无响应，用于演示卡住检测
+func simulateStalledTask(silence time.Duration) func(h *TaskHandle) interface{} {
+	return func(h *TaskHandle) interface{} {
+		h.Heartbeat("等待下游响应")
+		time.Sleep(silence)
+		h.SetProgress(100)
+		return "下游最终返回"
+	}
+}
+
+// simulateHangingTask 永远不会返回的任务，用于演示看门狗
+func simulateHangingTask() func() interface{} {
+	return func() interface{} {
+		<-make(chan struct{})
+		return nil
+	}
+}
+
 func calculateFactorial(n int) func() interface{} {
 	return func() interface{} {
 		if n < 0 {
@@ -211,24 +480,157 @@ func simulateNetworkRequest(url string) func() interface{} {
 	}
 }
 
+// demonstrateRunHistory 同一调度器执行两次，对比两次 Run 的任务耗时找出退化
+func demonstrateRunHistory() {
+	fmt.Println("\n=== 执行历史与对比 ===")
+	scheduler := NewTaskScheduler(WithHistoryLimit(5), WithBackend(BackendWorkStealing, 2))
+
+	// 网络延迟逐次增加，模拟下游变慢
+	latency := 50 * time.Millisecond
+	scheduler.AddTask(1, "计算1到10的和", calculateSum(10))
+	scheduler.AddTask(2, "计算4的阶乘", calculateFactorial(4))
+	scheduler.AddTask(3, "模拟慢速网络请求", func() interface{} {
+		time.Sleep(latency)
+		latency *= 3
+		return "ok"
+	})
+
+	base := scheduler.ExecuteTasks()
+	target := scheduler.ExecuteTasks()
+	scheduler.PrintResults()
+	PrintRunComparison(CompareRuns(base, target, 0.2))
+	fmt.Printf("   保留的历史执行记录: %d 条\n", len(scheduler.Runs()))
+}
+
+// simulateFlakyService 下游服务不可用时缓慢失败，恢复后正常返回
+func simulateFlakyService(up *atomic.Bool, name string) func() interface{} {
+	return func() interface{} {
+		time.Sleep(80 * time.Millisecond)
+		if !up.Load() {
+			panic(fmt.Sprintf("连接 %s 超时", name))
+		}
+		return fmt.Sprintf("%s 响应正常", name)
+	}
+}
+
+// demonstrateCircuitBreaker 下游服务故障时熔断器打开，后续同类任务快速失败，服务恢复后探测成功并关闭
+func demonstrateCircuitBreaker() {
+	fmt.Println("\n=== 按标签熔断 ===")
+	// 单个CPU槽位使任务依次执行，便于观察熔断器状态变化
+	scheduler := NewTaskScheduler(WithCapacity(1, 0), WithCircuitBreaker(3, 150*time.Millisecond))
+
+	var up atomic.Bool
+	for i := 1; i <= 5; i++ {
+		scheduler.AddTask(i, fmt.Sprintf("调用库存服务%d", i), simulateFlakyService(&up, "库存服务"), WithTag("库存服务"))
+	}
+	scheduler.AddTask(6, "修复库存服务", func() interface{} {
+		time.Sleep(200 * time.Millisecond)
+		up.Store(true)
+		return "库存服务已恢复"
+	}, WithTag("运维"))
+	for i := 7; i <= 8; i++ {
+		scheduler.AddTask(i, fmt.Sprintf("调用库存服务%d", i), simulateFlakyService(&up, "库存服务"), WithTag("库存服务"))
+	}
+
+	scheduler.ExecuteTasks()
+	scheduler.PrintResults()
+}
+
+// saveGoroutineDumps 将看门狗抓取的协程调用栈写入临时文件，便于事后排查
+func saveGoroutineDumps(dumps []GoroutineDump) {
+	for _, dump := range dumps {
+		f, err := os.CreateTemp("", "goroutine-dump-*.txt")
+		if err != nil {
+			fmt.Printf("保存协程调用栈失败: %v\n", err)
+			return
+		}
+		fmt.Fprintf(f, "# Run #%d 快照 #%d %s 卡住的任务ID: %v\n\n%s",
+			dump.RunID, dump.ID, dump.Time.Format(time.RFC3339Nano), dump.TaskIDs, dump.Stacks)
+		f.Close()
+		fmt.Printf("🧊 协程调用栈已保存到: %s\n", f.Name())
+	}
+}
+
 func main() {
+	dashboardAddr := flag.String("dashboard", "", "启动监控面板的监听地址，例如 :8080")
+	tracePath := flag.String("trace", "", "将任务执行的追踪数据导出到该文件")
+	traceFormat := flag.String("trace-format", string(TraceFormatChrome), "追踪数据格式: chrome 或 otlp")
+	flag.Parse()
+
 	// 执行题目1
 	printOddEvenNumbers()
+	demonstrateOrderedPrinter()
 
 	// 执行题目2
-	scheduler := NewTaskScheduler()
+	scheduler := NewTaskScheduler(
+		WithCapacity(4, 1024),
+		WithStallThreshold(300*time.Millisecond),
+		WithWatchdog(1500*time.Millisecond, true),
+		WithTenantPolicy("计算组", 2, 0),
+		WithTenantPolicy("网关组", 1, 0),
+		WithTenantPolicy("批处理组", 1, 2),
+	)
+	if *dashboardAddr != "" {
+		server, err := scheduler.StartDashboard(*dashboardAddr)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer server.Close()
+	}
+
+	// 批处理组先提交一大批小任务，公平调度保证其他租户的任务不会被饿死
+	for i := 1; i <= 8; i++ {
+		scheduler.AddTask(100+i, fmt.Sprintf("批量计算%d的阶乘", i), calculateFactorial(i), WithTenant("批处理组"))
+	}
+
+	// 添加各种类型的任务，计算密集型任务声明更多CPU槽位，网络请求任务主要占用内存
+	scheduler.AddTaskWithHandle(1, "计算1到100的和", calculateSumWithProgress(100), WithResources(2, 128), WithTenant("计算组"))
+	scheduler.AddTask(2, "计算5的阶乘", calculateFactorial(5), WithTenant("计算组"))
+	scheduler.AddTask(3, "模拟网络请求1", simulateNetworkRequest("https://api.example1.com"), WithResources(1, 512), WithTenant("网关组"))
+	scheduler.AddTask(4, "计算1到50的和", calculateSum(50), WithResources(2, 128), WithTenant("计算组"))
+	scheduler.AddTask(5, "计算7的阶乘", calculateFactorial(7), WithTenant("计算组"))
+	scheduler.AddTask(6, "模拟网络请求2", simulateNetworkRequest("https://api.example2.com/data"), WithResources(1, 512), WithTenant("网关组"))
+	scheduler.AddTaskWithHandle(7, "模拟卡顿任务", simulateStalledTask(500*time.Millisecond))
+	scheduler.AddTask(8, "模拟永不返回的任务", simulateHangingTask())
 
-	// 添加各种类型的任务
-	scheduler.AddTask(1, "计算1到100的和", calculateSum(100))
-	scheduler.AddTask(2, "计算5的阶乘", calculateFactorial(5))
-	scheduler.AddTask(3, "模拟网络请求1", simulateNetworkRequest("https://api.example1.com"))
-	scheduler.AddTask(4, "计算1到50的和", calculateSum(50))
-	scheduler.AddTask(5, "计算7的阶乘", calculateFactorial(7))
-	scheduler.AddTask(6, "模拟网络请求2", simulateNetworkRequest("https://api.example2.com/data"))
+	// 模拟事故响应：执行过程中暂停派发一段时间，已运行的任务继续执行
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		scheduler.Pause()
+		fmt.Printf("调度器状态: %+v\n", scheduler.Status())
+		time.Sleep(300 * time.Millisecond)
+		scheduler.Resume()
+		time.Sleep(200 * time.Millisecond)
+		scheduler.PrintProgress()
+	}()
 
 	// 并发执行所有任务
-	scheduler.ExecuteTasks()
+	run := scheduler.ExecuteTasks()
+	if *tracePath != "" {
+		if err := WriteTraceFile(*tracePath, run, TraceFormat(*traceFormat)); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("🔍 追踪数据已导出到 %s (%d 个span)\n", *tracePath, len(run.Spans))
+		}
+	}
 
 	// 打印执行结果统计
 	scheduler.PrintResults()
+	scheduler.PrintTenantStats()
+	scheduler.PrintResourceUtilization()
+	saveGoroutineDumps(run.GoroutineDumps)
+
+	demonstrateRunHistory()
+	demonstrateParallelHelpers()
+	demonstrateCircuitBreaker()
+	demonstrateSubTasks()
+
+	if *dashboardAddr != "" {
+		// 保持监控面板可访问，直到用户按下 Ctrl+C
+		fmt.Println("\n监控面板保持运行中，按 Ctrl+C 退出")
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		<-ctx.Done()
+	}
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncStrategy N 个协程之间传递执行权的同步策略
+type SyncStrategy int
+
+const (
+	// StrategyChannel 每个协程一个带缓冲的通道，执行完后向下一个协程的通道发送令牌
+	StrategyChannel SyncStrategy = iota
+	// StrategyCond 共享的 turn 变量配合 sync.Cond，轮到自己前在条件变量上等待
+	StrategyCond
+	// StrategySpin 原子变量保存 turn，轮到自己前自旋等待（期间让出处理器）
+	StrategySpin
+)
+
+func (s SyncStrategy) String() string {
+	switch s {
+	case StrategyChannel:
+		return "channel"
+	case StrategyCond:
+		return "sync.Cond"
+	case StrategySpin:
+		return "atomic自旋"
+	default:
+		return fmt.Sprintf("SyncStrategy(%d)", int(s))
+	}
+}
+
+// turnTaker 在 N 个参与者之间按 0,1,...,N-1 的顺序轮转执行权
+type turnTaker interface {
+	// wait 阻塞直到轮到参与者 id
+	wait(id int)
+	// pass 参与者 id 执行完毕，将执行权交给下一个参与者
+	pass(id int)
+}
+
+func newTurnTaker(strategy SyncStrategy, n int) turnTaker {
+	switch strategy {
+	case StrategyCond:
+		t := &condTurn{n: n}
+		t.cond = sync.NewCond(&t.mu)
+		return t
+	case StrategySpin:
+		return &spinTurn{n: int64(n)}
+	default:
+		t := &channelTurn{chs: make([]chan struct{}, n)}
+		for i := range t.chs {
+			t.chs[i] = make(chan struct{}, 1)
+		}
+		t.chs[0] <- struct{}{}
+		return t
+	}
+}
+
+type channelTurn struct {
+	chs []chan struct{}
+}
+
+func (t *channelTurn) wait(id int) { <-t.chs[id] }
+
+func (t *channelTurn) pass(id int) { t.chs[(id+1)%len(t.chs)] <- struct{}{} }
+
+type condTurn struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	n    int
+	turn int
+}
+
+func (t *condTurn) wait(id int) {
+	t.mu.Lock()
+	for t.turn != id {
+		t.cond.Wait()
+	}
+	t.mu.Unlock()
+}
+
+func (t *condTurn) pass(id int) {
+	t.mu.Lock()
+	t.turn = (id + 1) % t.n
+	t.mu.Unlock()
+	// 所有协程共用一个条件变量，只能广播唤醒后由各自检查是否轮到自己
+	t.cond.Broadcast()
+}
+
+type spinTurn struct {
+	n    int64
+	turn atomic.Int64
+}
+
+func (t *spinTurn) wait(id int) {
+	for t.turn.Load() != int64(id) {
+		// 协程数可能超过 GOMAXPROCS，让出处理器避免持有执行权的协程得不到调度
+		runtime.Gosched()
+	}
+}
+
+func (t *spinTurn) pass(id int) { t.turn.Store((int64(id) + 1) % t.n) }
+
+// OrderedPrinter 启动 N 个协程严格按轮转顺序输出 1..limit：
+// 第 i 个协程（从 0 开始）负责 i+1, i+1+N, i+1+2N ...，输出顺序与协程调度无关
+type OrderedPrinter struct {
+	workers  int
+	strategy SyncStrategy
+}
+
+// NewOrderedPrinter 创建使用指定同步策略的 N 协程轮转打印器
+func NewOrderedPrinter(workers int, strategy SyncStrategy) *OrderedPrinter {
+	return &OrderedPrinter{workers: max(workers, 1), strategy: strategy}
+}
+
+// Run 按 1..limit 的顺序依次回调 emit，worker 为负责该数字的协程编号，返回时所有协程均已退出
+func (p *OrderedPrinter) Run(limit int, emit func(worker, value int)) {
+	turn := newTurnTaker(p.strategy, p.workers)
+	var wg sync.WaitGroup
+	wg.Add(p.workers)
+	for w := 0; w < p.workers; w++ {
+		go func(worker int) {
+			defer wg.Done()
+			for v := worker + 1; v <= limit; v += p.workers {
+				turn.wait(worker)
+				emit(worker, v)
+				turn.pass(worker)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// demonstrateOrderedPrinter 三个协程使用不同策略严格轮转打印
+func demonstrateOrderedPrinter() {
+	fmt.Println("=== N协程严格轮转打印 ===")
+	for _, strategy := range []SyncStrategy{StrategyChannel, StrategyCond, StrategySpin} {
+		fmt.Printf("策略 %s: ", strategy)
+		NewOrderedPrinter(3, strategy).Run(12, func(worker, value int) {
+			fmt.Printf("G%d:%d ", worker+1, value)
+		})
+		fmt.Println()
+	}
+	fmt.Println()
+}
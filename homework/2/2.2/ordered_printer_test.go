@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// 每种策略下输出严格按 1..limit 的顺序，且第 k 个数字由第 (k-1)%N 个协程输出
+func TestOrderedPrinterRoundRobin(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for _, strategy := range []SyncStrategy{StrategyChannel, StrategyCond, StrategySpin} {
+		for _, workers := range []int{1, 2, 3, 7} {
+			for _, limit := range []int{0, 1, 10, 500} {
+				// 执行权在协程之间交接时建立 happens-before 关系，emit 无需额外加锁
+				var got []string
+				NewOrderedPrinter(workers, strategy).Run(limit, func(worker, value int) {
+					got = append(got, fmt.Sprintf("G%d:%d", worker, value))
+				})
+				want := make([]string, limit)
+				for i := range want {
+					want[i] = fmt.Sprintf("G%d:%d", i%workers, i+1)
+				}
+				if strings.Join(got, " ") != strings.Join(want, " ") {
+					t.Errorf("%s/workers=%d/limit=%d: 输出 %v, 期望 %v", strategy, workers, limit, got, want)
+				}
+			}
+		}
+	}
+}
+
+// 题目1 的奇偶数协程严格交替打印
+func TestPrintOddEvenNumbers(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	printOddEvenNumbers()
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want strings.Builder
+	want.WriteString("=== 题目1：协程打印奇偶数 ===\n")
+	for i := 1; i <= 10; i++ {
+		if i%2 == 1 {
+			fmt.Fprintf(&want, "奇数: %d\n", i)
+		} else {
+			fmt.Fprintf(&want, "偶数: %d\n", i)
+		}
+	}
+	want.WriteString("所有协程执行完成\n\n")
+	if string(out) != want.String() {
+		t.Errorf("输出 =\n%s\n期望\n%s", out, want.String())
+	}
+}
+
+// BenchmarkOrderedPrinter 对比各同步策略在不同协程数下每次交接执行权的开销
+func BenchmarkOrderedPrinter(b *testing.B) {
+	const handoffs = 10000
+	for _, workers := range []int{2, 4, 8} {
+		for _, strategy := range []SyncStrategy{StrategyChannel, StrategyCond, StrategySpin} {
+			b.Run(fmt.Sprintf("workers=%d/%s", workers, strategy), func(b *testing.B) {
+				b.ReportAllocs()
+				printer := NewOrderedPrinter(workers, strategy)
+				for i := 0; i < b.N; i++ {
+					printer.Run(handoffs, func(worker, value int) {})
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*handoffs), "ns/handoff")
+			})
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParallelOptions 并行辅助函数的配置
+type ParallelOptions struct {
+	// Workers 期望的并行度，用于计算默认分块大小，默认为 GOMAXPROCS；
+	// 实际同时执行的分块数由调度器的资源容量、租户配额和执行后端决定
+	Workers int
+	// ChunkSize 每个分块包含的元素数，默认将数据切分为约 Workers*4 块
+	ChunkSize int
+	// Resources 每个分块执行时向调度器申请的资源，默认与普通任务相同（1 个 CPU 槽位）
+	Resources Resources
+}
+
+func (o ParallelOptions) normalize(n int) ParallelOptions {
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = max((n+o.Workers*4-1)/(o.Workers*4), 1)
+	}
+	if o.Resources == (Resources{}) {
+		o.Resources = defaultTaskResources
+	}
+	return o
+}
+
+// ParallelFor 将 [0, n) 切分为若干分块，每个分块作为 h 的子任务提交给调度器并行执行 body。
+// 分块继承父任务的租户和标签，与普通任务一样经过公平队列、Pause、资源准入和熔断器；
+// 父任务等待分块期间让出所占的资源。body 返回错误或 panic 时所在分块失败，
+// 尚未执行完的其余分块随之取消；失败分块的错误按位置顺序合并后返回
+func ParallelFor(h *TaskHandle, n int, body func(i int) error, opts ParallelOptions) error {
+	if n <= 0 {
+		return nil
+	}
+	opts = opts.normalize(n)
+	if err := h.exec.ts.resources.fits(opts.Resources); err != nil {
+		return err
+	}
+
+	// 第一个失败的分块取消其余分块：排队中的不再派发，执行中的在下一个元素前停止
+	var mu sync.Mutex
+	var chunkIDs []int
+	stopped := false
+	stop := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		stopped = true
+		for _, id := range chunkIDs {
+			h.exec.cancelTask(id)
+		}
+	}
+
+	taskOpts := []TaskOption{WithResources(opts.Resources.CPU, opts.Resources.MemoryMB)}
+	if h.task.Tag != "" {
+		taskOpts = append(taskOpts, WithTag(h.task.Tag))
+	}
+	for start := 0; start < n; start += opts.ChunkSize {
+		end := min(start+opts.ChunkSize, n)
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			break
+		}
+		id := h.Spawn(fmt.Sprintf("%s 分块[%d, %d)", h.taskName, start, end), func(ch *TaskHandle) interface{} {
+			if err := runChunk(ch.Context(), start, end, body); err != nil {
+				ch.fail(fmt.Errorf("分块 [%d, %d) 执行失败: %w", start, end, err))
+				stop()
+			}
+			return nil
+		}, taskOpts...)
+		chunkIDs = append(chunkIDs, id)
+		mu.Unlock()
+	}
+
+	var errs []error
+	for _, result := range h.Wait() {
+		// 因其他分块失败而取消的分块不单独报告
+		if result.Error == nil || stopped && errors.Is(result.Error, ErrTaskCanceled) {
+			continue
+		}
+		errs = append(errs, result.Error)
+	}
+	return errors.Join(errs...)
+}
+
+// runChunk 顺序执行一个分块，遇到错误、panic 或取消时停止并返回
+func runChunk(ctx context.Context, start, end int, body func(i int) error) (err error) {
+	i := start
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("元素 %d: 任务执行panic: %v", i, r)
+		}
+	}()
+	done := ctx.Done()
+	for ; i < end; i++ {
+		select {
+		case <-done:
+			return fmt.Errorf("%w: 在元素 %d 前停止", ErrTaskCanceled, i)
+		default:
+		}
+		if err := body(i); err != nil {
+			return fmt.Errorf("元素 %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ParallelMap 并行地对 items 中的每个元素应用 fn，结果与输入一一对应。
+// 出错或被取消的元素在结果中保持零值，错误处理方式与 ParallelFor 相同
+func ParallelMap[T, R any](h *TaskHandle, items []T, fn func(T) (R, error), opts ParallelOptions) ([]R, error) {
+	results := make([]R, len(items))
+	err := ParallelFor(h, len(items), func(i int) error {
+		r, err := fn(items[i])
+		if err != nil {
+			return err
+		}
+		results[i] = r
+		return nil
+	}, opts)
+	return results, err
+}
+
+// demonstrateParallelHelpers 演示 ParallelMap/ParallelFor 对大量元素的分块并行处理
+func demonstrateParallelHelpers() {
+	fmt.Println("\n=== 并行 For/Map 辅助函数 ===")
+	scheduler := NewTaskScheduler(WithCapacity(4, 0))
+
+	items := make([]int, 1_000_000)
+	for i := range items {
+		items[i] = i + 1
+	}
+
+	// 分块作为子任务提交，与其他任务一起经过公平队列和资源准入
+	scheduler.AddTaskWithHandle(1, "并行求平方", func(h *TaskHandle) interface{} {
+		start := time.Now()
+		squares, err := ParallelMap(h, items, func(x int) (int, error) {
+			return x * x % 1000, nil
+		}, ParallelOptions{Workers: 4, ChunkSize: 250_000})
+		sum := 0
+		for _, v := range squares {
+			sum += v
+		}
+		fmt.Printf("ParallelMap 处理 %d 个元素, 结果求和: %d, 错误: %v, 耗时: %v\n",
+			len(items), sum, err, time.Since(start).Round(time.Microsecond))
+		return sum
+	})
+
+	// 个别元素出错或panic时所在分块失败，其余尚未完成的分块随之取消
+	var processed atomic.Int64
+	scheduler.AddTaskWithHandle(2, "并行校验", func(h *TaskHandle) interface{} {
+		err := ParallelFor(h, 100, func(i int) error {
+			switch i {
+			case 42:
+				return fmt.Errorf("无效输入")
+			case 77:
+				panic("索引越界")
+			}
+			processed.Add(1)
+			return nil
+		}, ParallelOptions{Workers: 4, ChunkSize: 10})
+		fmt.Printf("ParallelFor 处理了 %d 个元素, 错误汇总:\n%v\n", processed.Load(), err)
+		return err
+	})
+	scheduler.ExecuteTasks()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// runParallel 在调度器的一个任务中执行 fn，返回本次执行记录
+func runParallel(ts *TaskScheduler, fn func(h *TaskHandle) interface{}, opts ...TaskOption) *Run {
+	ts.AddTaskWithHandle(1, "并行任务", fn, opts...)
+	return ts.ExecuteTasks()
+}
+
+// chunkResults 返回父任务提交的分块子任务的结果
+func chunkResults(run *Run) []TaskResult {
+	var chunks []TaskResult
+	for _, result := range run.Results {
+		if result.ParentID == 1 {
+			chunks = append(chunks, result)
+		}
+	}
+	return chunks
+}
+
+// 每个元素恰好执行一次，结果与输入一一对应；分块作为子任务继承父任务的租户和标签
+func TestParallelMapOrder(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	ts := NewTaskScheduler(WithCapacity(4, 0), WithTenantPolicy("计算组", 1, 0))
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	var calls [1000]atomic.Int32
+	var squares []int
+	var mapErr error
+	run := runParallel(ts, func(h *TaskHandle) interface{} {
+		squares, mapErr = ParallelMap(h, items, func(x int) (int, error) {
+			calls[x].Add(1)
+			return x * x, nil
+		}, ParallelOptions{ChunkSize: 7})
+		return nil
+	}, WithTenant("计算组"), WithTag("计算"))
+
+	if mapErr != nil {
+		t.Fatalf("ParallelMap: %v", mapErr)
+	}
+	for i, v := range squares {
+		if v != i*i || calls[i].Load() != 1 {
+			t.Fatalf("元素 %d: 结果 %d, 调用 %d 次; 期望结果 %d, 调用 1 次", i, v, calls[i].Load(), i*i)
+		}
+	}
+	chunks := chunkResults(run)
+	if len(chunks) != (len(items)+6)/7 {
+		t.Errorf("分块子任务数 = %d, 期望 %d", len(chunks), (len(items)+6)/7)
+	}
+	for _, chunk := range chunks {
+		if chunk.Error != nil || chunk.Tenant != "计算组" || chunk.Tag != "计算" {
+			t.Errorf("分块 [%s]: 错误 %v, 租户 %q, 标签 %q", chunk.TaskName, chunk.Error, chunk.Tenant, chunk.Tag)
+		}
+	}
+	assertResourcesReleased(t, ts)
+}
+
+// 分块失败时其余分块取消，不再执行剩余元素；只报告真正出错的分块
+func TestParallelForErrorStopsRemainingWork(t *testing.T) {
+	errBad := errors.New("无效输入")
+	tests := []struct {
+		name string
+		body func(i int) error
+		want string
+	}{
+		{"返回错误", func(i int) error {
+			if i == 15 {
+				return errBad
+			}
+			return nil
+		}, "分块 [10, 20) 执行失败: 元素 15: 无效输入"},
+		{"panic", func(i int) error {
+			if i == 15 {
+				panic("索引越界")
+			}
+			return nil
+		}, "分块 [10, 20) 执行失败: 元素 15: 任务执行panic: 索引越界"},
+	}
+	for _, tt := range tests {
+		// 容量为 1 时分块按提交顺序逐个执行，失败后剩余分块都还在排队
+		ts := NewTaskScheduler(WithCapacity(1, 0))
+		var processed atomic.Int32
+		var err error
+		run := runParallel(ts, func(h *TaskHandle) interface{} {
+			err = ParallelFor(h, 100, func(i int) error {
+				if err := tt.body(i); err != nil {
+					return err
+				}
+				processed.Add(1)
+				return nil
+			}, ParallelOptions{ChunkSize: 10})
+			return nil
+		})
+
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: 错误 = %v, 期望 %q", tt.name, err, tt.want)
+		}
+		if errors.Is(err, ErrTaskCanceled) {
+			t.Errorf("%s: 被取消的分块不应出现在错误中: %v", tt.name, err)
+		}
+		if n := processed.Load(); n != 15 {
+			t.Errorf("%s: 执行了 %d 个元素, 期望失败后停止在 15 个", tt.name, n)
+		}
+		for _, chunk := range chunkResults(run)[2:] {
+			if !errors.Is(chunk.Error, ErrTaskCanceled) {
+				t.Errorf("%s: 分块 [%s] 错误 = %v, 期望被取消", tt.name, chunk.TaskName, chunk.Error)
+			}
+		}
+		assertResourcesReleased(t, ts)
+	}
+	if err := ParallelFor(nil, 0, nil, ParallelOptions{}); err != nil {
+		t.Errorf("空范围返回 %v, 期望 nil", err)
+	}
+}
+
+// 调度器暂停期间分块不会被派发，恢复后全部执行完毕，暂停时长计入分块的排队时间
+func TestParallelForWhilePaused(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	ts := NewTaskScheduler(WithCapacity(2, 0))
+	var processed atomic.Int32
+	var duringPause int32
+	var err error
+	run := runParallel(ts, func(h *TaskHandle) interface{} {
+		ts.Pause()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			duringPause = processed.Load()
+			ts.Resume()
+		}()
+		err = ParallelFor(h, 20, func(i int) error {
+			processed.Add(1)
+			return nil
+		}, ParallelOptions{ChunkSize: 5})
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("ParallelFor: %v", err)
+	}
+	if duringPause != 0 {
+		t.Errorf("暂停期间执行了 %d 个元素, 期望 0", duringPause)
+	}
+	if n := processed.Load(); n != 20 {
+		t.Errorf("恢复后执行了 %d 个元素, 期望 20", n)
+	}
+	for _, chunk := range chunkResults(run) {
+		if chunk.PausedTime < 50*time.Millisecond {
+			t.Errorf("分块 [%s] 的暂停时长 = %v, 期望包含暂停期间", chunk.TaskName, chunk.PausedTime)
+		}
+	}
+	assertResourcesReleased(t, ts)
+}
+
+// 分块继承父任务的标签，熔断器打开时快速失败而不执行 body
+func TestParallelForCircuitOpen(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(2, 0), WithCircuitBreaker(2, time.Minute))
+	var processed atomic.Int32
+	var err error
+	runParallel(ts, func(h *TaskHandle) interface{} {
+		openBreaker(t, ts.breakers, "库存服务")
+		err = ParallelFor(h, 30, func(i int) error {
+			processed.Add(1)
+			return nil
+		}, ParallelOptions{ChunkSize: 10})
+		return nil
+	}, WithTag("库存服务"))
+
+	if !errors.Is(err, ErrCircuitOpen) || strings.Count(err.Error(), "\n") != 2 {
+		t.Errorf("错误 = %v, 期望 3 个分块都因熔断器打开而失败", err)
+	}
+	if n := processed.Load(); n != 0 {
+		t.Errorf("熔断器打开时执行了 %d 个元素, 期望 0", n)
+	}
+	assertResourcesReleased(t, ts)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// pauseGate 控制调度循环是否允许派发新任务，并累计暂停时长
+type pauseGate struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	paused   bool
+	pausedAt time.Time
+	total    time.Duration
+}
+
+func newPauseGate() *pauseGate {
+	g := &pauseGate{}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused {
+		return false
+	}
+	g.paused = true
+	g.pausedAt = time.Now()
+	return true
+}
+
+func (g *pauseGate) resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused {
+		return false
+	}
+	g.paused = false
+	g.total += time.Since(g.pausedAt)
+	g.pausedAt = time.Time{}
+	g.cond.Broadcast()
+	return true
+}
+
+// wait 在暂停期间阻塞，恢复后返回
+func (g *pauseGate) wait() {
+	g.mu.Lock()
+	for g.paused {
+		g.cond.Wait()
+	}
+	g.mu.Unlock()
+}
+
+func (g *pauseGate) isPaused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// elapsed 返回截至目前的累计暂停时长（包含正在进行的暂停）
+// 两次调用的差值即为这段时间内的暂停时长
+func (g *pauseGate) elapsed() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	total := g.total
+	if g.paused {
+		total += time.Since(g.pausedAt)
+	}
+	return total
+}
+
+// Pause 暂停从队列派发新任务，已在运行的任务不受影响
+func (ts *TaskScheduler) Pause() {
+	if ts.pause.pause() {
+		fmt.Println("⏸️  调度器已暂停，不再派发新任务")
+	}
+}
+
+// Resume 恢复派发任务
+func (ts *TaskScheduler) Resume() {
+	if ts.pause.resume() {
+		fmt.Println("▶️  调度器已恢复派发任务")
+	}
+}
+
+// SchedulerStatus 调度器当前状态
+type SchedulerStatus struct {
+	Paused      bool          `json:"paused"`
+	PausedSince time.Time     `json:"pausedSince,omitzero"`
+	TotalPaused time.Duration `json:"totalPaused"`
+	Queued      int           `json:"queued"`
+	Running     int           `json:"running"`
+	Finished    int           `json:"finished"`
+}
+
+// Status 返回调度器的暂停状态和各状态任务数量
+func (ts *TaskScheduler) Status() SchedulerStatus {
+	ts.pause.mu.Lock()
+	status := SchedulerStatus{
+		Paused:      ts.pause.paused,
+		PausedSince: ts.pause.pausedAt,
+	}
+	ts.pause.mu.Unlock()
+	status.TotalPaused = ts.pause.elapsed()
+
+	for _, task := range ts.TaskStatuses() {
+		switch {
+		case task.State == TaskQueued:
+			status.Queued++
+		case task.State == TaskRunning:
+			status.Running++
+		case task.State.Finished():
+			status.Finished++
+		}
+	}
+	return status
+}
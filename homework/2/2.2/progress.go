@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskHandle 传递给任务函数的句柄，任务可通过它上报进度、自定义计数器和心跳
+type TaskHandle struct {
+	taskID   int
+	taskName string
+	exec     *execution
+	task     Task
+	lease    *taskLease
+
+	mu            sync.Mutex
+	percent       float64
+	counters      map[string]int64
+	message       string
+	lastHeartbeat time.Time
+	stalled       bool
+	everStalled   bool
+	// waiting 阻塞等待子任务期间不做心跳检查，看门狗也不计时；waited 累计的等待时间
+	waiting  bool
+	waited   time.Duration
+	children []int
+	pending  []chan TaskResult
+	// failure 任务通过 fail 报告的错误，任务返回后作为执行结果的错误
+	failure error
+}
+
+func newTaskHandle(e *execution, t Task, lease *taskLease) *TaskHandle {
+	return &TaskHandle{
+		taskID:        t.ID,
+		taskName:      t.Name,
+		exec:          e,
+		task:          t,
+		lease:         lease,
+		counters:      make(map[string]int64),
+		lastHeartbeat: time.Now(),
+	}
+}
+
+// SetProgress 上报完成百分比（0-100），同时视为一次心跳
+func (h *TaskHandle) SetProgress(percent float64) {
+	percent = min(max(percent, 0), 100)
+	h.mu.Lock()
+	h.percent = percent
+	h.beat()
+	h.mu.Unlock()
+}
+
+// AddCounter 累加自定义计数器，例如已处理的记录数，同时视为一次心跳
+func (h *TaskHandle) AddCounter(name string, delta int64) {
+	h.mu.Lock()
+	h.counters[name] += delta
+	h.beat()
+	h.mu.Unlock()
+}
+
+// Heartbeat 上报心跳和当前状态描述
+func (h *TaskHandle) Heartbeat(message string) {
+	h.mu.Lock()
+	h.message = message
+	h.beat()
+	h.mu.Unlock()
+}
+
+// fail 报告任务失败；任务函数返回后以 err 作为结果的错误，并计入熔断器的失败次数
+func (h *TaskHandle) fail(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failure == nil {
+		h.failure = err
+	}
+}
+
+func (h *TaskHandle) failed() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failure
+}
+
+// beat 刷新心跳时间，调用方需持有 h.mu
+func (h *TaskHandle) beat() {
+	h.lastHeartbeat = time.Now()
+	h.stalled = false
+}
+
+// TaskProgress 单个任务的进度快照
+type TaskProgress struct {
+	TaskID        int              `json:"taskId"`
+	TaskName      string           `json:"taskName"`
+	State         TaskState        `json:"state"`
+	Percent       float64          `json:"percent"`
+	Counters      map[string]int64 `json:"counters,omitempty"`
+	Message       string           `json:"message,omitempty"`
+	LastHeartbeat time.Time        `json:"lastHeartbeat,omitzero"`
+	Stalled       bool             `json:"stalled"`
+}
+
+// BatchProgress 整批任务的进度汇总
+type BatchProgress struct {
+	Percent float64        `json:"percent"`
+	Stalled int            `json:"stalled"`
+	Tasks   []TaskProgress `json:"tasks"`
+}
+
+func (h *TaskHandle) snapshot() TaskProgress {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return TaskProgress{
+		TaskID:        h.taskID,
+		TaskName:      h.taskName,
+		Percent:       h.percent,
+		Counters:      maps.Clone(h.counters),
+		Message:       h.message,
+		LastHeartbeat: h.lastHeartbeat,
+		Stalled:       h.stalled,
+	}
+}
+
+// checkStalled 心跳超过 threshold 未更新时标记为卡住，返回是否为新发现的卡住
+func (h *TaskHandle) checkStalled(now time.Time, threshold time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stalled || h.waiting || now.Sub(h.lastHeartbeat) < threshold {
+		return false
+	}
+	h.stalled = true
+	h.everStalled = true
+	return true
+}
+
+// waitState 返回任务是否正在等待子任务，以及此前累计的等待时间
+func (h *TaskHandle) waitState() (waiting bool, waited time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.waiting, h.waited
+}
+
+func (h *TaskHandle) wasStalled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.everStalled
+}
+
+// Progress 汇总所有任务的进度：已结束的任务计 100%，排队中计 0%，
+// 运行中的任务以句柄上报的百分比为准（未使用句柄的任务计 0%）
+func (ts *TaskScheduler) Progress() BatchProgress {
+	statuses := ts.TaskStatuses()
+
+	ts.handleMu.Lock()
+	handles := maps.Clone(ts.handles)
+	ts.handleMu.Unlock()
+
+	var batch BatchProgress
+	total := 0.0
+	for _, status := range statuses {
+		progress := TaskProgress{TaskID: status.TaskID, TaskName: status.TaskName}
+		if h, ok := handles[status.TaskID]; ok {
+			progress = h.snapshot()
+		}
+		progress.State = status.State
+		if status.State.Finished() {
+			progress.Percent = 100
+			progress.Stalled = false
+		}
+		if progress.Stalled {
+			batch.Stalled++
+		}
+		total += progress.Percent
+		batch.Tasks = append(batch.Tasks, progress)
+	}
+	if len(statuses) > 0 {
+		batch.Percent = total / float64(len(statuses))
+	}
+	sort.Slice(batch.Tasks, func(i, j int) bool {
+		return batch.Tasks[i].TaskID < batch.Tasks[j].TaskID
+	})
+	return batch
+}
+
+// PrintProgress 打印整批任务进度以及运行中任务的详情
+func (ts *TaskScheduler) PrintProgress() {
+	batch := ts.Progress()
+	filled := int(batch.Percent / 5)
+	fmt.Printf("📈 批次进度: [%s%s] %.1f%%",
+		strings.Repeat("█", filled), strings.Repeat("░", 20-filled), batch.Percent)
+	if batch.Stalled > 0 {
+		fmt.Printf(" (⚠️ %d 个任务疑似卡住)", batch.Stalled)
+	}
+	fmt.Println()
+	for _, task := range batch.Tasks {
+		if task.State != TaskRunning {
+			continue
+		}
+		fmt.Printf("   任务 [%s] %.1f%%", task.TaskName, task.Percent)
+		for _, name := range sortedKeys(task.Counters) {
+			fmt.Printf(" %s=%d", name, task.Counters[name])
+		}
+		if task.Message != "" {
+			fmt.Printf(" - %s", task.Message)
+		}
+		if task.Stalled {
+			fmt.Printf(" ⚠️ 心跳停止于 %s", task.LastHeartbeat.Format("15:04:05.000"))
+		}
+		fmt.Println()
+	}
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// monitorStalls 定期检查使用句柄的运行中任务，心跳超时则标记为卡住并发布事件，直到 done 关闭
+func (ts *TaskScheduler) monitorStalls(done <-chan struct{}) {
+	if ts.stallThreshold <= 0 {
+		return
+	}
+	ticker := time.NewTicker(ts.stallThreshold / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			ts.handleMu.Lock()
+			handles := make([]*TaskHandle, 0, len(ts.handles))
+			for _, h := range ts.handles {
+				handles = append(handles, h)
+			}
+			ts.handleMu.Unlock()
+
+			for _, h := range handles {
+				if !h.checkStalled(now, ts.stallThreshold) {
+					continue
+				}
+				message := fmt.Sprintf("超过 %v 未收到心跳，疑似卡住", ts.stallThreshold)
+				fmt.Printf("⚠️  任务 [%s] %s\n", h.taskName, message)
+				ts.events.publish(TaskEvent{
+					Time:     now,
+					TaskID:   h.taskID,
+					TaskName: h.taskName,
+					State:    TaskRunning,
+					Message:  message,
+				})
+			}
+		}
+	}
+}
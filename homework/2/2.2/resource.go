@@ -0,0 +1,239 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resources 描述任务运行所需（或调度器可提供）的资源权重
+// CPU 为 CPU 槽位数，MemoryMB 为内存（MB）；容量为 0 表示该维度不限制
+type Resources struct {
+	CPU      int `json:"cpu"`
+	MemoryMB int `json:"memoryMB"`
+}
+
+func (r Resources) String() string {
+	return fmt.Sprintf("CPU:%d 内存:%dMB", r.CPU, r.MemoryMB)
+}
+
+// defaultTaskResources 未声明资源的任务按 1 个 CPU 槽位计算
+var defaultTaskResources = Resources{CPU: 1}
+
+// weightedSemaphore 加权信号量，等待者按 FIFO 顺序被唤醒，避免重任务被饿死
+type weightedSemaphore struct {
+	size    int64
+	cur     int64
+	mu      sync.Mutex
+	waiters list.List
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newWeightedSemaphore(size int64) *weightedSemaphore {
+	return &weightedSemaphore{size: size}
+}
+
+// Acquire 获取 n 个单位的权重，不足时阻塞直到其他持有者释放
+func (s *weightedSemaphore) Acquire(n int64) {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return
+	}
+
+	w := semaphoreWaiter{n: n, ready: make(chan struct{})}
+	s.waiters.PushBack(w)
+	s.mu.Unlock()
+	<-w.ready
+}
+
+// Release 归还 n 个单位的权重，并按顺序唤醒能够满足的等待者
+func (s *weightedSemaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	if s.cur < 0 {
+		panic("weightedSemaphore: 释放的权重超过已持有的权重")
+	}
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			break
+		}
+		w := next.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			// 队首等待者资源仍不足时停止唤醒，保证 FIFO
+			break
+		}
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
+
+// ResourceSample 某一时刻的资源占用快照
+type ResourceSample struct {
+	Time     time.Time
+	CPU      int
+	MemoryMB int
+}
+
+// resourcePool 管理调度器的资源容量，负责准入控制并记录占用随时间的变化
+type resourcePool struct {
+	capacity Resources
+	cpu      *weightedSemaphore
+	memory   *weightedSemaphore
+
+	mu      sync.Mutex
+	inUse   Resources
+	samples []ResourceSample
+}
+
+func newResourcePool(capacity Resources) *resourcePool {
+	p := &resourcePool{capacity: capacity}
+	if capacity.CPU > 0 {
+		p.cpu = newWeightedSemaphore(int64(capacity.CPU))
+	}
+	if capacity.MemoryMB > 0 {
+		p.memory = newWeightedSemaphore(int64(capacity.MemoryMB))
+	}
+	return p
+}
+
+// fits 检查单个任务的资源需求是否可能被满足
+func (p *resourcePool) fits(req Resources) error {
+	if p.capacity.CPU > 0 && req.CPU > p.capacity.CPU {
+		return fmt.Errorf("资源需求超过调度器容量: 需要 %d 个CPU槽位, 容量 %d", req.CPU, p.capacity.CPU)
+	}
+	if p.capacity.MemoryMB > 0 && req.MemoryMB > p.capacity.MemoryMB {
+		return fmt.Errorf("资源需求超过调度器容量: 需要 %dMB 内存, 容量 %dMB", req.MemoryMB, p.capacity.MemoryMB)
+	}
+	return nil
+}
+
+// acquire 阻塞直到任务所需资源全部可用
+// 所有调用方都按 CPU、内存的固定顺序获取，而内存只会由已运行的任务持有并最终释放，因此不会产生死锁
+func (p *resourcePool) acquire(req Resources) {
+	if p.cpu != nil && req.CPU > 0 {
+		p.cpu.Acquire(int64(req.CPU))
+	}
+	if p.memory != nil && req.MemoryMB > 0 {
+		p.memory.Acquire(int64(req.MemoryMB))
+	}
+	p.record(req.CPU, req.MemoryMB)
+}
+
+// release 归还任务占用的资源
+func (p *resourcePool) release(req Resources) {
+	p.record(-req.CPU, -req.MemoryMB)
+	if p.memory != nil && req.MemoryMB > 0 {
+		p.memory.Release(int64(req.MemoryMB))
+	}
+	if p.cpu != nil && req.CPU > 0 {
+		p.cpu.Release(int64(req.CPU))
+	}
+}
+
+func (p *resourcePool) record(cpu, memoryMB int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse.CPU += cpu
+	p.inUse.MemoryMB += memoryMB
+	p.samples = append(p.samples, ResourceSample{
+		Time:     time.Now(),
+		CPU:      p.inUse.CPU,
+		MemoryMB: p.inUse.MemoryMB,
+	})
+}
+
+// reset 清空采样记录，以 start 作为新一轮执行的起点
+func (p *resourcePool) reset(start time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.samples = []ResourceSample{{Time: start, CPU: p.inUse.CPU, MemoryMB: p.inUse.MemoryMB}}
+}
+
+func (p *resourcePool) snapshot() []ResourceSample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	samples := make([]ResourceSample, len(p.samples))
+	copy(samples, p.samples)
+	return samples
+}
+
+// ResourceUsage 返回最近一次执行期间的资源占用采样
+func (ts *TaskScheduler) ResourceUsage() []ResourceSample {
+	return ts.resources.snapshot()
+}
+
+// PrintResourceUtilization 打印资源占用随时间的变化以及峰值、时间加权平均利用率
+func (ts *TaskScheduler) PrintResourceUtilization() {
+	fmt.Println("\n=== 资源利用率 ===")
+	samples := ts.resources.snapshot()
+	capacity := ts.resources.capacity
+	if len(samples) < 2 {
+		fmt.Println("   暂无资源占用记录")
+		return
+	}
+
+	fmt.Printf("   容量: %s\n", formatCapacity(capacity))
+	start := samples[0].Time
+	var peak Resources
+	var cpuArea, memArea float64
+	for i, s := range samples {
+		peak.CPU = max(peak.CPU, s.CPU)
+		peak.MemoryMB = max(peak.MemoryMB, s.MemoryMB)
+		if i > 0 {
+			// 上一个采样值一直持续到本次采样，按时间加权
+			prev := samples[i-1]
+			dt := s.Time.Sub(prev.Time).Seconds()
+			cpuArea += float64(prev.CPU) * dt
+			memArea += float64(prev.MemoryMB) * dt
+		}
+		fmt.Printf("   +%-8v CPU %s 内存 %s\n",
+			s.Time.Sub(start).Round(time.Millisecond),
+			utilizationBar(s.CPU, capacity.CPU, ""),
+			utilizationBar(s.MemoryMB, capacity.MemoryMB, "MB"))
+	}
+
+	elapsed := samples[len(samples)-1].Time.Sub(start).Seconds()
+	fmt.Printf("   峰值占用: CPU %d, 内存 %dMB\n", peak.CPU, peak.MemoryMB)
+	if elapsed > 0 {
+		fmt.Printf("   平均占用: CPU %.2f, 内存 %.0fMB\n", cpuArea/elapsed, memArea/elapsed)
+		if capacity.CPU > 0 {
+			fmt.Printf("   平均CPU利用率: %.1f%%\n", cpuArea/elapsed/float64(capacity.CPU)*100)
+		}
+		if capacity.MemoryMB > 0 {
+			fmt.Printf("   平均内存利用率: %.1f%%\n", memArea/elapsed/float64(capacity.MemoryMB)*100)
+		}
+	}
+}
+
+func formatCapacity(c Resources) string {
+	cpu, mem := "不限", "不限"
+	if c.CPU > 0 {
+		cpu = fmt.Sprintf("%d", c.CPU)
+	}
+	if c.MemoryMB > 0 {
+		mem = fmt.Sprintf("%dMB", c.MemoryMB)
+	}
+	return fmt.Sprintf("CPU %s, 内存 %s", cpu, mem)
+}
+
+// utilizationBar 以 10 格进度条展示占用比例；容量不限时只显示数值
+func utilizationBar(used, capacity int, unit string) string {
+	if capacity <= 0 {
+		return fmt.Sprintf("%d%s", used, unit)
+	}
+	filled := used * 10 / capacity
+	return fmt.Sprintf("[%s%s] %d/%d%s",
+		strings.Repeat("█", filled), strings.Repeat("░", 10-filled), used, capacity, unit)
+}
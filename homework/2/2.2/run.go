@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultHistoryLimit 默认保留的历史执行记录数
+const defaultHistoryLimit = 10
+
+// Run 一次 ExecuteTasks 的执行记录
+type Run struct {
+	ID        int
+	StartTime time.Time
+	EndTime   time.Time
+	Results   []TaskResult
+	// Spans 本次执行的追踪数据，可通过 ExportTrace 导出
+	Spans []Span
+	// BreakerTransitions 本次执行期间的熔断器状态变化
+	BreakerTransitions []BreakerTransition
+	// GoroutineDumps 本次执行期间看门狗发现卡住任务时抓取的协程调用栈
+	GoroutineDumps []GoroutineDump
+}
+
+// Duration 本次执行的墙钟耗时
+func (r *Run) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// Result 按任务ID查找本次执行的结果
+func (r *Run) Result(taskID int) (TaskResult, bool) {
+	for _, result := range r.Results {
+		if result.TaskID == taskID {
+			return result, true
+		}
+	}
+	return TaskResult{}, false
+}
+
+// WithHistoryLimit 设置保留的历史执行记录数，超出时丢弃最早的记录
+func WithHistoryLimit(n int) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.historyLimit = max(n, 1)
+	}
+}
+
+// recordRun 保存执行记录，并按上限裁剪历史
+func (ts *TaskScheduler) recordRun(run *Run) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.runs = append(ts.runs, run)
+	if len(ts.runs) > ts.historyLimit {
+		ts.runs = ts.runs[len(ts.runs)-ts.historyLimit:]
+	}
+}
+
+// Runs 返回保留的历史执行记录，按执行顺序排列
+func (ts *TaskScheduler) Runs() []*Run {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	runs := make([]*Run, len(ts.runs))
+	copy(runs, ts.runs)
+	return runs
+}
+
+// LastRun 返回最近一次执行记录，没有执行过时返回 nil
+func (ts *TaskScheduler) LastRun() *Run {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if len(ts.runs) == 0 {
+		return nil
+	}
+	return ts.runs[len(ts.runs)-1]
+}
+
+// TaskDurationDiff 同一任务在两次执行中的耗时对比
+type TaskDurationDiff struct {
+	TaskID     int
+	TaskName   string
+	Base       time.Duration
+	Target     time.Duration
+	Delta      time.Duration
+	Ratio      float64
+	Regression bool
+}
+
+// RunComparison 两次执行的对比结果
+type RunComparison struct {
+	Base      *Run
+	Target    *Run
+	Threshold float64
+	Diffs     []TaskDurationDiff
+	// OnlyInBase/OnlyInTarget 只在其中一次执行中出现的任务ID
+	OnlyInBase   []int
+	OnlyInTarget []int
+}
+
+// Regressions 返回耗时退化的任务
+func (c RunComparison) Regressions() []TaskDurationDiff {
+	var regressions []TaskDurationDiff
+	for _, diff := range c.Diffs {
+		if diff.Regression {
+			regressions = append(regressions, diff)
+		}
+	}
+	return regressions
+}
+
+// CompareRuns 按任务ID对比两次执行的耗时；target 比 base 慢超过 threshold（例如 0.2 表示 20%）即视为退化。
+// 结果按耗时增量降序排列
+func CompareRuns(base, target *Run, threshold float64) RunComparison {
+	comparison := RunComparison{Base: base, Target: target, Threshold: threshold}
+
+	seen := make(map[int]bool)
+	for _, baseResult := range base.Results {
+		targetResult, ok := target.Result(baseResult.TaskID)
+		if !ok {
+			comparison.OnlyInBase = append(comparison.OnlyInBase, baseResult.TaskID)
+			continue
+		}
+		seen[baseResult.TaskID] = true
+
+		diff := TaskDurationDiff{
+			TaskID:   baseResult.TaskID,
+			TaskName: targetResult.TaskName,
+			Base:     baseResult.ExecuteTime,
+			Target:   targetResult.ExecuteTime,
+			Delta:    targetResult.ExecuteTime - baseResult.ExecuteTime,
+		}
+		if baseResult.ExecuteTime > 0 {
+			diff.Ratio = float64(targetResult.ExecuteTime) / float64(baseResult.ExecuteTime)
+			diff.Regression = diff.Ratio > 1+threshold
+		}
+		comparison.Diffs = append(comparison.Diffs, diff)
+	}
+	for _, targetResult := range target.Results {
+		if !seen[targetResult.TaskID] {
+			comparison.OnlyInTarget = append(comparison.OnlyInTarget, targetResult.TaskID)
+		}
+	}
+
+	sort.Slice(comparison.Diffs, func(i, j int) bool {
+		return comparison.Diffs[i].Delta > comparison.Diffs[j].Delta
+	})
+	sort.Ints(comparison.OnlyInBase)
+	sort.Ints(comparison.OnlyInTarget)
+	return comparison
+}
+
+// PrintRunComparison 打印两次执行的耗时对比，标出退化的任务
+func PrintRunComparison(c RunComparison) {
+	fmt.Printf("\n=== 执行对比: Run #%d -> Run #%d (退化阈值 %.0f%%) ===\n",
+		c.Base.ID, c.Target.ID, c.Threshold*100)
+	fmt.Printf("   总耗时: %v -> %v\n",
+		c.Base.Duration().Round(time.Millisecond), c.Target.Duration().Round(time.Millisecond))
+	for _, diff := range c.Diffs {
+		icon := "  "
+		if diff.Regression {
+			icon = "🔺"
+		}
+		sign := "+"
+		if diff.Delta < 0 {
+			sign = ""
+		}
+		fmt.Printf("%s 任务ID: %d, 名称: %s, 耗时: %v -> %v (%s%v, x%.2f)\n",
+			icon, diff.TaskID, diff.TaskName,
+			diff.Base.Round(time.Millisecond), diff.Target.Round(time.Millisecond),
+			sign, diff.Delta.Round(time.Millisecond), diff.Ratio)
+	}
+	if len(c.OnlyInBase) > 0 {
+		fmt.Printf("   仅在 Run #%d 中出现的任务: %v\n", c.Base.ID, c.OnlyInBase)
+	}
+	if len(c.OnlyInTarget) > 0 {
+		fmt.Printf("   仅在 Run #%d 中出现的任务: %v\n", c.Target.ID, c.OnlyInTarget)
+	}
+	fmt.Printf("   退化任务数: %d\n", len(c.Regressions()))
+}
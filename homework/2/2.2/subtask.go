@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Context 返回任务的上下文；任务或其任一祖先任务被取消时 Done 关闭
+func (h *TaskHandle) Context() context.Context {
+	return h.task.ctx
+}
+
+// Spawn 在当前执行中提交一个子任务，返回子任务ID。
+// 子任务默认继承父任务的租户，与其他任务一样经过公平队列和准入控制；
+// 父任务被取消时子任务随之取消。Spawn 只能在任务函数返回前调用
+func (h *TaskHandle) Spawn(name string, fn func(h *TaskHandle) interface{}, opts ...TaskOption) int {
+	e := h.exec
+	child := Task{
+		ID:           e.nextTaskID(),
+		Name:         name,
+		HandleFunc:   fn,
+		Resources:    defaultTaskResources,
+		Tenant:       h.task.Tenant,
+		ParentID:     h.taskID,
+		parentSpanID: h.task.spanID,
+	}
+	for _, opt := range opts {
+		opt(&child)
+	}
+
+	waiter := make(chan TaskResult, 1)
+	e.mu.Lock()
+	e.waiters[child.ID] = waiter
+	e.mu.Unlock()
+
+	h.mu.Lock()
+	h.children = append(h.children, child.ID)
+	h.pending = append(h.pending, waiter)
+	h.beat()
+	h.mu.Unlock()
+
+	fmt.Printf("任务 [%s] 提交子任务 [%s] (ID: %d)\n", h.taskName, name, child.ID)
+	e.enqueue(child, h.task.ctx)
+	return child.ID
+}
+
+// Wait 等待此前提交且尚未等待过的子任务全部结束，按提交顺序返回它们的结果。
+// 等待期间父任务让出所占的资源和租户并发配额，工作池类后端会临时补充工作协程，
+// 因此父任务等待子任务不会造成死锁
+func (h *TaskHandle) Wait() []TaskResult {
+	h.mu.Lock()
+	pending := h.pending
+	h.pending = nil
+	h.waiting = len(pending) > 0
+	h.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 资源由 lease 统一管理：被看门狗放弃的任务已由调度循环归还资源，此时既不再让出也不再重新占用
+	start := time.Now()
+	suspended := h.lease != nil && h.lease.suspend()
+	unblock := h.exec.backend.blocking()
+
+	results := make([]TaskResult, 0, len(pending))
+	for _, waiter := range pending {
+		results = append(results, <-waiter)
+	}
+
+	unblock()
+	if suspended {
+		h.lease.resume()
+	}
+
+	h.mu.Lock()
+	h.waiting = false
+	h.waited += time.Since(start)
+	h.beat()
+	h.mu.Unlock()
+	return results
+}
+
+func (h *TaskHandle) childIDs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.children) == 0 {
+		return nil
+	}
+	ids := make([]int, len(h.children))
+	copy(ids, h.children)
+	return ids
+}
+
+// CancelTask 取消当前执行中的任务：排队中的任务不再派发，运行中的任务可通过 TaskHandle.Context 感知，
+// 已提交和之后提交的子任务一并取消。任务不存在或当前没有正在进行的执行时返回 false
+func (ts *TaskScheduler) CancelTask(id int) bool {
+	ts.mu.Lock()
+	e := ts.current
+	ts.mu.Unlock()
+	if e == nil {
+		return false
+	}
+	return e.cancelTask(id)
+}
+
+// printTaskTree 按父子关系缩进打印一次执行的任务结果
+func printTaskTree(results []TaskResult) {
+	byID := make(map[int]TaskResult, len(results))
+	var roots []int
+	for _, result := range results {
+		byID[result.TaskID] = result
+		if result.ParentID == 0 {
+			roots = append(roots, result.TaskID)
+		}
+	}
+	sort.Ints(roots)
+
+	var walk func(id, depth int)
+	walk = func(id, depth int) {
+		result, ok := byID[id]
+		if !ok {
+			return
+		}
+		status := fmt.Sprintf("结果: %v", result.Result)
+		if result.Error != nil {
+			status = fmt.Sprintf("错误: %v", result.Error)
+		}
+		fmt.Printf("%*s- [%d] %s, 耗时: %v, %s\n", depth*2, "", result.TaskID, result.TaskName,
+			result.ExecuteTime.Round(time.Millisecond), status)
+		for _, child := range result.ChildIDs {
+			walk(child, depth+1)
+		}
+	}
+	for _, id := range roots {
+		walk(id, 0)
+	}
+}
+
+// crawlPage 模拟抓取一页数据，返回本页条目数以及是否还有下一页
+func crawlPage(page int) (items int, hasMore bool) {
+	time.Sleep(time.Duration(20+page*5) * time.Millisecond)
+	return 10 + page, page < 4
+}
+
+// demonstrateSubTasks 演示运行中的任务提交子任务：分页抓取时边抓边发现新的页面，
+// 以及取消父任务时级联取消其子任务
+func demonstrateSubTasks() {
+	fmt.Println("\n=== 子任务提交与级联取消 ===")
+	scheduler := NewTaskScheduler(WithCapacity(2, 0), WithBackend(BackendWorkStealing, 2))
+
+	// 每一页抓取完成后，若还有下一页则提交抓取下一页的子任务；父任务等待所有页面后汇总
+	var crawl func(page int) func(h *TaskHandle) interface{}
+	crawl = func(page int) func(h *TaskHandle) interface{} {
+		return func(h *TaskHandle) interface{} {
+			items, hasMore := crawlPage(page)
+			h.AddCounter("items", int64(items))
+			if hasMore {
+				h.Spawn(fmt.Sprintf("抓取第%d页", page+1), crawl(page+1))
+			}
+			for _, child := range h.Wait() {
+				if n, ok := child.Result.(int); ok {
+					items += n
+				}
+			}
+			return items
+		}
+	}
+	scheduler.AddTaskWithHandle(1, "抓取第1页", crawl(1))
+
+	// 父任务提交多个耗时子任务后被取消，子任务通过上下文感知取消并提前结束
+	scheduler.AddTaskWithHandle(2, "批量导出", func(h *TaskHandle) interface{} {
+		for i := 1; i <= 3; i++ {
+			h.Spawn(fmt.Sprintf("导出分片%d", i), func(h *TaskHandle) interface{} {
+				select {
+				case <-time.After(2 * time.Second):
+					return "完成"
+				case <-h.Context().Done():
+					return "中止"
+				}
+			})
+		}
+		h.Wait()
+		return "全部导出"
+	})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Println("⛔ 取消任务 2 及其子任务")
+		scheduler.CancelTask(2)
+	}()
+
+	run := scheduler.ExecuteTasks()
+	fmt.Println("\n任务树:")
+	printTaskTree(run.Results)
+}
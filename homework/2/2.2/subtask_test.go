@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// assertResourcesReleased 检查执行结束后资源池没有残留占用
+func assertResourcesReleased(t *testing.T, ts *TaskScheduler) {
+	t.Helper()
+	p := ts.resources
+	p.mu.Lock()
+	inUse := p.inUse
+	p.mu.Unlock()
+	if inUse != (Resources{}) {
+		t.Errorf("执行结束后资源占用 = %v, 期望全部归还", inUse)
+	}
+	p.cpu.mu.Lock()
+	cur := p.cpu.cur
+	p.cpu.mu.Unlock()
+	if cur != 0 {
+		t.Errorf("CPU 信号量仍持有 %d 个单位", cur)
+	}
+}
+
+// 父任务等待子任务的总时间超过看门狗阈值时不应被判定为卡住
+func TestWatchdogSkipsTaskWaitingForChildren(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(1, 0), WithWatchdog(100*time.Millisecond, true))
+	ts.AddTaskWithHandle(1, "父任务", func(h *TaskHandle) interface{} {
+		for i := 1; i <= 3; i++ {
+			h.Spawn(fmt.Sprintf("子任务%d", i), func(h *TaskHandle) interface{} {
+				time.Sleep(60 * time.Millisecond)
+				return nil
+			})
+		}
+		return len(h.Wait())
+	})
+
+	run := ts.ExecuteTasks()
+	for _, result := range run.Results {
+		if result.Error != nil || result.Stuck {
+			t.Errorf("任务 %d [%s] 失败: %v (卡住: %v)", result.TaskID, result.TaskName, result.Error, result.Stuck)
+		}
+		if result.TaskID == 1 && result.Result != 3 {
+			t.Errorf("父任务结果 = %v, 期望 3", result.Result)
+		}
+	}
+	assertResourcesReleased(t, ts)
+}
+
+// 子任务被放弃时，等待它的父任务正常拿到错误结果，资源不会被重复归还，容量也不会泄漏
+func TestWatchdogAbandonChildOfWaitingParent(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(1, 0), WithWatchdog(100*time.Millisecond, true))
+	ts.AddTaskWithHandle(1, "父任务", func(h *TaskHandle) interface{} {
+		h.Spawn("子任务", func(h *TaskHandle) interface{} {
+			time.Sleep(300 * time.Millisecond)
+			return "子任务完成"
+		})
+		return h.Wait()[0].Error
+	})
+
+	run := ts.ExecuteTasks()
+	for _, result := range run.Results {
+		switch result.TaskID {
+		case 1:
+			if result.Error != nil {
+				t.Errorf("父任务失败: %v", result.Error)
+			}
+			if err, _ := result.Result.(error); !errors.Is(err, ErrTaskAbandoned) {
+				t.Errorf("父任务收到的子任务错误 = %v, 期望 ErrTaskAbandoned", result.Result)
+			}
+		default:
+			if !errors.Is(result.Error, ErrTaskAbandoned) {
+				t.Errorf("子任务错误 = %v, 期望 ErrTaskAbandoned", result.Error)
+			}
+		}
+	}
+	assertResourcesReleased(t, ts)
+
+	ts.AddTask(2, "后续任务", func() interface{} { return "ok" })
+	done := make(chan struct{})
+	go func() {
+		ts.ExecuteTasks()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("后续执行未能获得资源，容量已泄漏")
+	}
+}
+
+// dispatchLease 模拟调度循环派发任务：出队、准入后生成 lease
+func dispatchLease(ts *TaskScheduler, e *execution, id int) *taskLease {
+	e.queue.push(Task{ID: id, Name: fmt.Sprintf("任务%d", id), Resources: defaultTaskResources})
+	task, _ := e.queue.next()
+	ts.resources.acquire(task.Resources)
+	return &taskLease{e: e, t: task, held: true}
+}
+
+// assertQueueDrained 检查租户配额和在途任务数都已归还
+func assertQueueDrained(t *testing.T, q *fairQueue) {
+	t.Helper()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.inflight != 0 {
+		t.Errorf("在途任务数 = %d, 期望 0", q.inflight)
+	}
+	for name, tq := range q.tenants {
+		if tq.running != 0 {
+			t.Errorf("租户 %q 运行数 = %d, 期望 0", name, tq.running)
+		}
+	}
+}
+
+// 等待子任务期间任务被放弃时，资源只由调度循环归还一次，等待结束后也不再重新占用
+func TestTaskLeaseAbandonDuringWait(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(1, 0))
+	e := ts.newExecution(&Run{ID: 1})
+	lease := dispatchLease(ts, e, 1)
+
+	if !lease.suspend() {
+		t.Fatal("持有资源的任务应能让出资源")
+	}
+	lease.end() // 看门狗放弃任务，调度循环结束它
+	lease.resume()
+	if lease.suspend() {
+		t.Error("已结束的任务不应再让出资源")
+	}
+	assertResourcesReleased(t, ts)
+	assertQueueDrained(t, e.queue)
+}
+
+// 任务在 resume 阻塞等待资源时被放弃，获得资源后必须立即归还
+func TestTaskLeaseEndWhileResuming(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(1, 0))
+	e := ts.newExecution(&Run{ID: 1})
+	lease := dispatchLease(ts, e, 1)
+	lease.suspend()
+
+	other := dispatchLease(ts, e, 2)
+	resumed := make(chan struct{})
+	go func() {
+		lease.resume()
+		close(resumed)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	lease.end()
+	other.end()
+
+	select {
+	case <-resumed:
+	case <-time.After(time.Second):
+		t.Fatal("resume 未返回")
+	}
+	assertResourcesReleased(t, ts)
+	assertQueueDrained(t, e.queue)
+}
+
+func TestAbandonedTaskReportsError(t *testing.T) {
+	ts := NewTaskScheduler(WithCapacity(1, 0), WithWatchdog(50*time.Millisecond, true))
+	release := make(chan struct{})
+	defer close(release)
+	ts.AddTask(1, "卡住的任务", func() interface{} {
+		<-release
+		return nil
+	})
+	run := ts.ExecuteTasks()
+	if len(run.Results) != 1 || !errors.Is(run.Results[0].Error, ErrTaskAbandoned) {
+		t.Fatalf("结果 = %+v, 期望 ErrTaskAbandoned", run.Results)
+	}
+	assertResourcesReleased(t, ts)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultTenant 未指定租户的任务归属的默认租户
+const defaultTenant = "default"
+
+// TenantPolicy 租户的调度策略
+// Weight 为公平调度权重（每轮获得的配额与之成正比），MaxRunning 为同时运行的任务数上限，0 表示不限制
+type TenantPolicy struct {
+	Weight     int
+	MaxRunning int
+}
+
+var defaultTenantPolicy = TenantPolicy{Weight: 1}
+
+// WithTenantPolicy 为租户设置公平调度权重和并发配额
+func WithTenantPolicy(tenant string, weight, maxRunning int) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.tenantPolicies[tenant] = TenantPolicy{Weight: max(weight, 1), MaxRunning: maxRunning}
+	}
+}
+
+// WithTenant 指定任务所属租户
+func WithTenant(tenant string) TaskOption {
+	return func(t *Task) {
+		t.Tenant = tenant
+	}
+}
+
+// tenantQueue 单个租户的待调度任务
+type tenantQueue struct {
+	name    string
+	policy  TenantPolicy
+	tasks   []Task
+	running int
+	deficit int
+	// inTurn 表示本轮已为该租户补充过配额，避免在同一轮中重复累加
+	inTurn bool
+}
+
+func (q *tenantQueue) atQuota() bool {
+	return q.policy.MaxRunning > 0 && q.running >= q.policy.MaxRunning
+}
+
+// fairQueue 按赤字轮转（Deficit Round Robin）在租户之间公平派发任务
+// 每轮为有积压的租户补充 Weight 个单位的配额，任务消耗的配额等于其 CPU 槽位数（至少为 1），
+// 因此大批量提交任务的租户无法饿死其他租户
+type fairQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	policies map[string]TenantPolicy
+	tenants  map[string]*tenantQueue
+	active   []*tenantQueue
+	cursor   int
+	// inflight 已入队但尚未结束的任务数；运行中的任务还可能提交子任务，因此归零前队列不算耗尽
+	inflight int
+}
+
+func newFairQueue(policies map[string]TenantPolicy) *fairQueue {
+	q := &fairQueue{
+		policies: policies,
+		tenants:  make(map[string]*tenantQueue),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func taskCost(t Task) int {
+	return max(t.Resources.CPU, 1)
+}
+
+// push 将任务加入其租户的队列
+func (q *fairQueue) push(t Task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	tq, ok := q.tenants[t.Tenant]
+	if !ok {
+		policy, ok := q.policies[t.Tenant]
+		if !ok {
+			policy = defaultTenantPolicy
+		}
+		tq = &tenantQueue{name: t.Tenant, policy: policy}
+		q.tenants[t.Tenant] = tq
+	}
+	if len(tq.tasks) == 0 {
+		q.active = append(q.active, tq)
+	}
+	tq.tasks = append(tq.tasks, t)
+	q.inflight++
+	q.cond.Broadcast()
+}
+
+// next 按 DRR 选出下一个要派发的任务；所有有积压的租户都已达到并发配额，
+// 或队列暂时为空但仍有任务在运行时阻塞；队列为空且所有任务都已结束时返回 false
+func (q *fairQueue) next() (Task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		if len(q.active) == 0 {
+			if q.inflight == 0 {
+				return Task{}, false
+			}
+			q.cond.Wait()
+			continue
+		}
+		if q.allAtQuota() {
+			q.cond.Wait()
+			continue
+		}
+
+		q.cursor %= len(q.active)
+		tq := q.active[q.cursor]
+		if tq.atQuota() {
+			tq.inTurn = false
+			q.cursor++
+			continue
+		}
+		if !tq.inTurn {
+			tq.deficit += tq.policy.Weight
+			tq.inTurn = true
+		}
+
+		head := tq.tasks[0]
+		if cost := taskCost(head); tq.deficit >= cost {
+			tq.tasks = tq.tasks[1:]
+			tq.deficit -= cost
+			tq.running++
+			if len(tq.tasks) == 0 {
+				// 租户积压清空后退出轮转，剩余配额作废
+				tq.deficit = 0
+				tq.inTurn = false
+				q.active = append(q.active[:q.cursor], q.active[q.cursor+1:]...)
+			}
+			return head, true
+		}
+
+		// 配额不足以派发队首任务，轮到下一个租户
+		tq.inTurn = false
+		q.cursor++
+	}
+}
+
+// allAtQuota 调用方需持有 q.mu
+func (q *fairQueue) allAtQuota() bool {
+	for _, tq := range q.active {
+		if !tq.atQuota() {
+			return false
+		}
+	}
+	return true
+}
+
+// done 任务结束后归还租户的并发配额
+func (q *fairQueue) done(tenant string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inflight--
+	if tq, ok := q.tenants[tenant]; ok {
+		tq.running--
+	}
+	q.cond.Broadcast()
+}
+
+// suspend 运行中的任务阻塞等待子任务期间暂时让出租户的并发配额
+func (q *fairQueue) suspend(tenant string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if tq, ok := q.tenants[tenant]; ok {
+		tq.running--
+		q.cond.Broadcast()
+	}
+}
+
+// resume 等待结束后重新占用并发配额；不等待配额空出（可能暂时超过上限），避免与子任务互相等待
+func (q *fairQueue) resume(tenant string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if tq, ok := q.tenants[tenant]; ok {
+		tq.running++
+	}
+}
+
+// TenantStat 单个租户的执行统计
+type TenantStat struct {
+	Tenant       string
+	Policy       TenantPolicy
+	Total        int
+	Success      int
+	Failed       int
+	TotalTime    time.Duration
+	AvgQueueTime time.Duration
+	// Share 该租户消耗的 CPU 槽位时间占全部租户的比例
+	Share float64
+}
+
+// TenantStats 按租户汇总最近一次执行的结果
+func (ts *TaskScheduler) TenantStats() []TenantStat {
+	results := ts.Results()
+	stats := make(map[string]*TenantStat)
+	cpuTime := make(map[string]float64)
+	totalCPUTime := 0.0
+	queueTime := make(map[string]time.Duration)
+
+	for _, result := range results {
+		stat, ok := stats[result.Tenant]
+		if !ok {
+			policy, ok := ts.tenantPolicies[result.Tenant]
+			if !ok {
+				policy = defaultTenantPolicy
+			}
+			stat = &TenantStat{Tenant: result.Tenant, Policy: policy}
+			stats[result.Tenant] = stat
+		}
+		stat.Total++
+		if result.Error != nil {
+			stat.Failed++
+		} else {
+			stat.Success++
+		}
+		stat.TotalTime += result.ExecuteTime
+		queueTime[result.Tenant] += result.QueueTime
+
+		used := result.ExecuteTime.Seconds() * float64(max(result.Resources.CPU, 1))
+		cpuTime[result.Tenant] += used
+		totalCPUTime += used
+	}
+
+	list := make([]TenantStat, 0, len(stats))
+	for tenant, stat := range stats {
+		stat.AvgQueueTime = queueTime[tenant] / time.Duration(stat.Total)
+		if totalCPUTime > 0 {
+			stat.Share = cpuTime[tenant] / totalCPUTime
+		}
+		list = append(list, *stat)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Tenant < list[j].Tenant
+	})
+	return list
+}
+
+// PrintTenantStats 打印各租户的执行统计，与 PrintResults 配合使用
+func (ts *TaskScheduler) PrintTenantStats() {
+	fmt.Println("\n=== 租户执行统计 ===")
+	for _, stat := range ts.TenantStats() {
+		quota := "不限"
+		if stat.Policy.MaxRunning > 0 {
+			quota = fmt.Sprintf("%d", stat.Policy.MaxRunning)
+		}
+		fmt.Printf("🏢 租户: %s (权重: %d, 并发配额: %s)\n", stat.Tenant, stat.Policy.Weight, quota)
+		fmt.Printf("   任务数: %d, 成功: %d, 失败: %d\n", stat.Total, stat.Success, stat.Failed)
+		fmt.Printf("   总耗时: %v, 平均排队: %v, CPU占用份额: %.1f%%\n",
+			stat.TotalTime, stat.AvgQueueTime, stat.Share*100)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Span 一段有起止时间的执行过程
+// 每个任务生成一棵 span 树：task（从入队到结束）下包含 queue（排队）和 attempt（一次执行尝试），
+// attempt 下包含 run（任务函数本身的执行）；子任务的 task span 挂在父任务的 task span 下
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Start        time.Time
+	End          time.Time
+	TaskID       int
+	Attributes   map[string]interface{}
+	Error        string
+}
+
+// Duration span 的持续时间
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+func newTraceID() string { return randomHex(16) }
+
+func newSpanID() string { return randomHex(8) }
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成追踪ID失败: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// spanRecorder 收集一次 Run 中产生的 span
+type spanRecorder struct {
+	traceID string
+	mu      sync.Mutex
+	spans   []Span
+}
+
+func newSpanRecorder() *spanRecorder {
+	return &spanRecorder{traceID: newTraceID()}
+}
+
+// recordTask 为一个任务生成 task/queue/attempt/run 四个 span
+// 被拒绝的任务没有 admittedAt，只生成 task span
+func (r *spanRecorder) recordTask(t Task, queuedAt, admittedAt, finishedAt time.Time, result TaskResult) {
+	attrs := map[string]interface{}{
+		"task.id":      t.ID,
+		"task.name":    t.Name,
+		"task.tenant":  t.Tenant,
+		"resource.cpu": t.Resources.CPU,
+		"resource.mem": t.Resources.MemoryMB,
+	}
+	errMessage := ""
+	if result.Error != nil {
+		errMessage = result.Error.Error()
+	}
+
+	if t.ParentID != 0 {
+		attrs["task.parent_id"] = t.ParentID
+	}
+	spanID := t.spanID
+	if spanID == "" {
+		spanID = newSpanID()
+	}
+	taskSpan := Span{
+		TraceID:      r.traceID,
+		SpanID:       spanID,
+		ParentSpanID: t.parentSpanID,
+		Name:         "task " + t.Name,
+		Start:        queuedAt,
+		End:          finishedAt,
+		TaskID:       t.ID,
+		Attributes:   attrs,
+		Error:        errMessage,
+	}
+	spans := []Span{taskSpan}
+
+	if !admittedAt.IsZero() {
+		queueSpan := Span{
+			TraceID:      r.traceID,
+			SpanID:       newSpanID(),
+			ParentSpanID: taskSpan.SpanID,
+			Name:         "queue",
+			Start:        queuedAt,
+			End:          admittedAt,
+			TaskID:       t.ID,
+			Attributes:   map[string]interface{}{"paused.ms": result.PausedTime.Milliseconds()},
+		}
+		attemptSpan := Span{
+			TraceID:      r.traceID,
+			SpanID:       newSpanID(),
+			ParentSpanID: taskSpan.SpanID,
+			Name:         "attempt",
+			Start:        admittedAt,
+			End:          finishedAt,
+			TaskID:       t.ID,
+			Attributes:   map[string]interface{}{"attempt": 1},
+			Error:        errMessage,
+		}
+		runSpan := Span{
+			TraceID:      r.traceID,
+			SpanID:       newSpanID(),
+			ParentSpanID: attemptSpan.SpanID,
+			Name:         "run",
+			Start:        result.StartTime,
+			End:          result.StartTime.Add(result.ExecuteTime),
+			TaskID:       t.ID,
+			Attributes:   map[string]interface{}{"stalled": result.Stalled, "stuck": result.Stuck},
+			Error:        errMessage,
+		}
+		spans = append(spans, queueSpan, attemptSpan, runSpan)
+	}
+
+	r.mu.Lock()
+	r.spans = append(r.spans, spans...)
+	r.mu.Unlock()
+}
+
+// sorted 按开始时间返回所有 span
+func (r *spanRecorder) sorted() []Span {
+	r.mu.Lock()
+	spans := make([]Span, len(r.spans))
+	copy(spans, r.spans)
+	r.mu.Unlock()
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Start.Before(spans[j].Start)
+	})
+	return spans
+}
+
+// TraceFormat 追踪数据的导出格式
+type TraceFormat string
+
+const (
+	// TraceFormatChrome Chrome trace-event 格式，可在 chrome://tracing 或 Perfetto 中打开
+	TraceFormatChrome TraceFormat = "chrome"
+	// TraceFormatOTLP OTLP/JSON 格式，与 OpenTelemetry Collector 的文件导出格式一致
+	TraceFormatOTLP TraceFormat = "otlp"
+)
+
+// ExportTrace 将一次 Run 的 span 以指定格式写入 w
+func ExportTrace(w io.Writer, run *Run, format TraceFormat) error {
+	var doc interface{}
+	switch format {
+	case TraceFormatChrome:
+		doc = chromeTrace(run)
+	case TraceFormatOTLP:
+		doc = otlpTrace(run)
+	default:
+		return fmt.Errorf("不支持的追踪导出格式: %q", format)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteTraceFile 将一次 Run 的 span 导出到本地文件
+func WriteTraceFile(path string, run *Run, format TraceFormat) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建追踪文件失败: %w", err)
+	}
+	if err := ExportTrace(f, run, format); err != nil {
+		f.Close()
+		return fmt.Errorf("导出追踪数据失败: %w", err)
+	}
+	return f.Close()
+}
+
+type chromeEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   float64                `json:"ts"`
+	Dur  float64                `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// chromeTrace 每个 Run 对应一个进程，每个任务对应一个线程，span 以完整事件（ph=X）表示，
+// 同一线程上时间嵌套的事件会显示为父子层级
+func chromeTrace(run *Run) map[string]interface{} {
+	events := []chromeEvent{{
+		Name: "process_name", Ph: "M", Pid: run.ID,
+		Args: map[string]interface{}{"name": fmt.Sprintf("Run #%d", run.ID)},
+	}}
+	named := make(map[int]bool)
+	for _, span := range run.Spans {
+		if !named[span.TaskID] {
+			named[span.TaskID] = true
+			events = append(events, chromeEvent{
+				Name: "thread_name", Ph: "M", Pid: run.ID, Tid: span.TaskID,
+				Args: map[string]interface{}{"name": fmt.Sprintf("任务 %d", span.TaskID)},
+			})
+		}
+
+		args := map[string]interface{}{
+			"traceId": span.TraceID,
+			"spanId":  span.SpanID,
+		}
+		if span.ParentSpanID != "" {
+			args["parentSpanId"] = span.ParentSpanID
+		}
+		if span.Error != "" {
+			args["error"] = span.Error
+		}
+		for k, v := range span.Attributes {
+			args[k] = v
+		}
+		events = append(events, chromeEvent{
+			Name: span.Name,
+			Cat:  "scheduler",
+			Ph:   "X",
+			Ts:   float64(span.Start.UnixNano()) / 1e3,
+			Dur:  float64(span.Duration().Nanoseconds()) / 1e3,
+			Pid:  run.ID,
+			Tid:  span.TaskID,
+			Args: args,
+		})
+	}
+	return map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	}
+}
+
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+// otlpValue 将属性值转换为 OTLP AnyValue；按 OTLP/JSON 约定 int64 以字符串编码
+func otlpValue(v interface{}) map[string]interface{} {
+	switch x := v.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": x}
+	case bool:
+		return map[string]interface{}{"boolValue": x}
+	case int:
+		return map[string]interface{}{"intValue": strconv.Itoa(x)}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(x, 10)}
+	case float64:
+		return map[string]interface{}{"doubleValue": x}
+	default:
+		return map[string]interface{}{"stringValue": fmt.Sprint(x)}
+	}
+}
+
+func otlpAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpValue(attrs[k])})
+	}
+	return kvs
+}
+
+func otlpTrace(run *Run) map[string]interface{} {
+	spans := make([]otlpSpan, 0, len(run.Spans))
+	for _, span := range run.Spans {
+		status := otlpStatus{Code: 1} // STATUS_CODE_OK
+		if span.Error != "" {
+			status = otlpStatus{Code: 2, Message: span.Error} // STATUS_CODE_ERROR
+		}
+		spans = append(spans, otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              1, // SPAN_KIND_INTERNAL
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            status,
+		})
+	}
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": otlpAttributes(map[string]interface{}{
+						"service.name": "task-scheduler",
+						"run.id":       run.ID,
+					}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "go_homework/task-scheduler"},
+						"spans": spans,
+					},
+				},
+			},
+		},
+	}
+}
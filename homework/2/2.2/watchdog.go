@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTaskAbandoned 任务被看门狗判定为卡住并放弃等待
+var ErrTaskAbandoned = errors.New("任务运行超时已被放弃")
+
+// WithWatchdog 启用卡住任务看门狗：任务运行超过 threshold 即被标记为卡住并抓取所有协程的调用栈；
+// abandon 为 true 时不再等待卡住的任务，直接以 ErrTaskAbandoned 结束它并继续执行其余任务。
+// 注意 Go 无法强制终止协程，被放弃任务的协程仍会在后台运行直到其函数返回
+func WithWatchdog(threshold time.Duration, abandon bool) SchedulerOption {
+	return func(ts *TaskScheduler) {
+		ts.watchdog.threshold = threshold
+		ts.watchdog.abandon = abandon
+	}
+}
+
+// GoroutineDump 发现卡住任务时抓取的全部协程调用栈
+// 任务函数在带有 task_id/task 标签的协程中运行，可据此在调用栈中定位对应任务
+type GoroutineDump struct {
+	// ID 在所属 Run 内从 1 开始编号，TaskResult.StuckDumpID 引用该编号
+	ID      int
+	RunID   int
+	Time    time.Time
+	TaskIDs []int
+	Stacks  string
+}
+
+type runningTask struct {
+	task      Task
+	handle    *TaskHandle
+	startedAt time.Time
+	stuck     bool
+	dumpID    int
+	abandon   chan struct{}
+}
+
+// watchdog 记录运行中的任务，定期检查是否有任务运行超过阈值
+type watchdog struct {
+	threshold time.Duration
+	abandon   bool
+
+	mu      sync.Mutex
+	running map[int]*runningTask
+	// runID/dumps 当前 Run 的编号和抓取的调用栈，每次执行开始时清空
+	runID int
+	dumps []GoroutineDump
+}
+
+func newWatchdog() *watchdog {
+	return &watchdog{running: make(map[int]*runningTask)}
+}
+
+func (w *watchdog) track(t Task, handle *TaskHandle) *runningTask {
+	rt := &runningTask{task: t, handle: handle, startedAt: time.Now(), abandon: make(chan struct{})}
+	w.mu.Lock()
+	w.running[t.ID] = rt
+	w.mu.Unlock()
+	return rt
+}
+
+// beginRun 开始新的一次执行，之前抓取的调用栈已随上一个 Run 保存
+func (w *watchdog) beginRun(runID int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.runID = runID
+	w.dumps = nil
+}
+
+// runDumps 返回当前 Run 抓取的调用栈
+func (w *watchdog) runDumps() []GoroutineDump {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dumps := make([]GoroutineDump, len(w.dumps))
+	copy(dumps, w.dumps)
+	return dumps
+}
+
+// untrack 移除运行记录，返回任务是否曾被判定为卡住及对应调用栈的编号
+func (w *watchdog) untrack(t Task) (stuck bool, dumpID int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rt, ok := w.running[t.ID]
+	if !ok {
+		return false, 0
+	}
+	delete(w.running, t.ID)
+	return rt.stuck, rt.dumpID
+}
+
+// collectStuck 标记新超时的任务并为它们分配下一份调用栈的编号，调用方需持有 w.mu
+func (w *watchdog) collectStuck(now time.Time) []*runningTask {
+	var stuck []*runningTask
+	for _, rt := range w.running {
+		if rt.stuck {
+			continue
+		}
+		if ran, ok := rt.runTime(now); ok && ran >= w.threshold {
+			rt.stuck = true
+			rt.dumpID = len(w.dumps) + 1
+			stuck = append(stuck, rt)
+		}
+	}
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].task.ID < stuck[j].task.ID
+	})
+	return stuck
+}
+
+// runTime 返回任务扣除等待子任务时间后的运行时长；正在等待子任务时返回 false，
+// 与心跳检查一样不计时，父任务等待耗时较长的子任务不算卡住
+func (rt *runningTask) runTime(now time.Time) (time.Duration, bool) {
+	ran := now.Sub(rt.startedAt)
+	if rt.handle == nil {
+		return ran, true
+	}
+	waiting, waited := rt.handle.waitState()
+	return ran - waited, !waiting
+}
+
+// runWatchdog 定期检查运行中的任务，直到 done 关闭
+func (ts *TaskScheduler) runWatchdog(done <-chan struct{}) {
+	w := ts.watchdog
+	if w.threshold <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.threshold / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			w.mu.Lock()
+			stuck := w.collectStuck(now)
+			if len(stuck) == 0 {
+				w.mu.Unlock()
+				continue
+			}
+			// 先占位，保证分配的编号与 dumps 中的位置一致
+			dump := GoroutineDump{ID: stuck[0].dumpID, RunID: w.runID, Time: now}
+			w.dumps = append(w.dumps, dump)
+			w.mu.Unlock()
+
+			dump.Stacks = captureGoroutines()
+			for _, rt := range stuck {
+				dump.TaskIDs = append(dump.TaskIDs, rt.task.ID)
+				ran, _ := rt.runTime(now)
+				message := fmt.Sprintf("已运行 %v，超过阈值 %v，判定为卡住",
+					ran.Round(time.Millisecond), w.threshold)
+				fmt.Printf("🧊 任务 [%s] %s\n", rt.task.Name, message)
+				ts.events.publish(TaskEvent{
+					Time:     now,
+					TaskID:   rt.task.ID,
+					TaskName: rt.task.Name,
+					State:    TaskRunning,
+					Message:  message,
+				})
+				if w.abandon {
+					close(rt.abandon)
+				}
+			}
+
+			w.mu.Lock()
+			w.dumps[dump.ID-1] = dump
+			w.mu.Unlock()
+		}
+	}
+}
+
+// captureGoroutines 抓取所有协程的调用栈，相同调用栈合并显示，并附带 pprof 标签
+func captureGoroutines() string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return fmt.Sprintf("抓取协程调用栈失败: %v", err)
+	}
+	return buf.String()
+}
+
+// GoroutineDumps 返回正在进行或最近一次执行中看门狗抓取的协程调用栈记录，
+// 更早的执行见对应 Run 的 GoroutineDumps
+func (ts *TaskScheduler) GoroutineDumps() []GoroutineDump {
+	return ts.watchdog.runDumps()
+}
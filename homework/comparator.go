@@ -0,0 +1,175 @@
+// 可组合的多级比较器
+package main
+
+import (
+	"cmp"
+	"time"
+)
+
+// Comparator 三路比较函数：a < b 返回负数，a == b 返回 0，a > b 返回正数，
+// 可直接传给 slices.SortFunc / slices.SortStableFunc
+type Comparator[T any] func(a, b T) int
+
+// By 按 key 升序比较；浮点数的 NaN 排在最前
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// ByDesc 按 key 降序比较
+func ByDesc[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return By(key).Reverse()
+}
+
+// ByFunc 按 key 比较，key 之间使用自定义的三路比较函数（例如 time.Time.Compare）
+func ByFunc[T, K any](key func(T) K, compare func(a, b K) int) Comparator[T] {
+	return func(a, b T) int {
+		return compare(key(a), key(b))
+	}
+}
+
+// ByTime 按时间升序比较；零值时间按最早的时间处理，需要排在最后时配合 ZeroLast 使用
+func ByTime[T any](key func(T) time.Time) Comparator[T] {
+	return ByFunc(key, time.Time.Compare)
+}
+
+// ByRank 按给定顺序比较：出现在 order 中的值按其位置排序，未列出的值排在所有列出的值之后
+func ByRank[T any, K comparable](key func(T) K, order ...K) Comparator[T] {
+	rank := make(map[K]int, len(order))
+	for i, v := range order {
+		if _, ok := rank[v]; !ok {
+			rank[v] = i
+		}
+	}
+	return By(func(t T) int {
+		if r, ok := rank[key(t)]; ok {
+			return r
+		}
+		return len(order)
+	})
+}
+
+// ZeroLast key 为零值（例如未填写的入职日期、空部门）的元素排在非零值之后，两者都为零值或都非零时视为相等
+func ZeroLast[T any, K comparable](key func(T) K) Comparator[T] {
+	var zero K
+	return func(a, b T) int {
+		za, zb := key(a) == zero, key(b) == zero
+		switch {
+		case za == zb:
+			return 0
+		case za:
+			return 1
+		default:
+			return -1
+		}
+	}
+}
+
+// FromLess 将 sort.Slice 风格的 less 函数转换为三路比较器
+func FromLess[T any](less func(a, b T) bool) Comparator[T] {
+	return func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	}
+}
+
+// Then 当前比较器判定相等时，使用 next 继续比较；nil 比较器视为所有元素相等
+func (c Comparator[T]) Then(next Comparator[T]) Comparator[T] {
+	if c == nil {
+		return next
+	}
+	if next == nil {
+		return c
+	}
+	return func(a, b T) int {
+		if r := c(a, b); r != 0 {
+			return r
+		}
+		return next(a, b)
+	}
+}
+
+// Reverse 反转比较方向
+func (c Comparator[T]) Reverse() Comparator[T] {
+	if c == nil {
+		return nil
+	}
+	return func(a, b T) int {
+		return c(b, a)
+	}
+}
+
+// Compare 比较两个元素；nil 比较器视为所有元素相等
+func (c Comparator[T]) Compare(a, b T) int {
+	if c == nil {
+		return 0
+	}
+	return c(a, b)
+}
+
+// Less 转换为 sort.Slice / sort.SliceStable 需要的按下标比较函数
+func (c Comparator[T]) Less(s []T) func(i, j int) bool {
+	return func(i, j int) bool {
+		return c.Compare(s[i], s[j]) < 0
+	}
+}
+
+// NilsFirst 将元素比较器提升为指针比较器，nil 指针排在最前
+func NilsFirst[T any](c Comparator[T]) Comparator[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		case b == nil:
+			return 1
+		default:
+			return c.Compare(*a, *b)
+		}
+	}
+}
+
+// NilsLast 将元素比较器提升为指针比较器，nil 指针排在最后
+func NilsLast[T any](c Comparator[T]) Comparator[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		case b == nil:
+			return -1
+		default:
+			return c.Compare(*a, *b)
+		}
+	}
+}
+
+// 员工常用比较器
+var (
+	EmployeeByID         = By(func(e BestPracticeEmployee) int { return e.ID })
+	EmployeeByName       = By(func(e BestPracticeEmployee) string { return e.Name })
+	EmployeeByDepartment = By(func(e BestPracticeEmployee) string { return e.Department })
+	EmployeeBySalary     = By(func(e BestPracticeEmployee) float64 { return e.Salary })
+	EmployeeByHireDate   = ZeroLast(func(e BestPracticeEmployee) time.Time { return e.HireDate }).
+				Then(ByTime(func(e BestPracticeEmployee) time.Time { return e.HireDate }))
+)
+
+// 订单常用比较器
+var (
+	OrderByID       = By(func(o BestPracticeOrder) string { return o.ID })
+	OrderByAmount   = By(func(o BestPracticeOrder) float64 { return o.Amount })
+	OrderByPriority = By(func(o BestPracticeOrder) int { return o.Priority })
+	OrderByDate     = ZeroLast(func(o BestPracticeOrder) time.Time { return o.Date }).
+			Then(ByTime(func(o BestPracticeOrder) time.Time { return o.Date }))
+	// OrderUrgentFirst urgent 状态的订单排在其他订单之前
+	OrderUrgentFirst = ByRank(func(o BestPracticeOrder) string { return o.Status }, "urgent")
+)
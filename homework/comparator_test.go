@@ -0,0 +1,206 @@
+package main
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func sign(n int) int {
+	return cmp.Compare(n, 0)
+}
+
+var (
+	day1 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestEmployeeComparators(t *testing.T) {
+	alice := BestPracticeEmployee{ID: 1, Name: "Alice", Department: "Eng", Salary: 9000, HireDate: day2}
+	bob := BestPracticeEmployee{ID: 2, Name: "Bob", Department: "Eng", Salary: 12000, HireDate: day1}
+	carol := BestPracticeEmployee{ID: 3, Name: "Carol", Department: "Sales", Salary: 9000}
+	noDept := BestPracticeEmployee{ID: 4, Name: "Dave", Salary: 9000, HireDate: day1}
+
+	tests := []struct {
+		name string
+		cmp  Comparator[BestPracticeEmployee]
+		a, b BestPracticeEmployee
+		want int
+	}{
+		{"By 升序", EmployeeBySalary, alice, bob, -1},
+		{"By 相等", EmployeeBySalary, alice, carol, 0},
+		{"ByDesc 降序", ByDesc(func(e BestPracticeEmployee) float64 { return e.Salary }), alice, bob, 1},
+		{"ByDesc 相等", ByDesc(func(e BestPracticeEmployee) float64 { return e.Salary }), alice, carol, 0},
+		{"Then 第一级决定", EmployeeByDepartment.Then(EmployeeBySalary), carol, bob, 1},
+		{"Then 第一级相等时用第二级", EmployeeByDepartment.Then(EmployeeBySalary), bob, alice, 1},
+		{"Then 多级全部相等", EmployeeByDepartment.Then(EmployeeBySalary), alice, alice, 0},
+		{"Reverse", EmployeeByName.Reverse(), alice, bob, 1},
+		{"Reverse 相等", EmployeeBySalary.Reverse(), alice, carol, 0},
+		{"Reverse 后 Then 只反转第一级", EmployeeByDepartment.Reverse().Then(EmployeeByID), alice, bob, -1},
+		{"ZeroLast 零值在后", ZeroLast(func(e BestPracticeEmployee) string { return e.Department }), noDept, alice, 1},
+		{"ZeroLast 非零值在前", ZeroLast(func(e BestPracticeEmployee) string { return e.Department }), alice, noDept, -1},
+		{"ZeroLast 都非零视为相等", ZeroLast(func(e BestPracticeEmployee) string { return e.Department }), alice, carol, 0},
+		{"ZeroLast 都为零视为相等", ZeroLast(func(e BestPracticeEmployee) string { return e.Department }), noDept, noDept, 0},
+		{"入职日期 早的在前", EmployeeByHireDate, bob, alice, -1},
+		{"入职日期 未填写的在后", EmployeeByHireDate, carol, alice, 1},
+		{"入职日期 都未填写", EmployeeByHireDate, carol, carol, 0},
+		{"ByRank 列出的在前", ByRank(func(e BestPracticeEmployee) string { return e.Department }, "Sales", "Eng"), carol, alice, -1},
+		{"ByRank 未列出的在后", ByRank(func(e BestPracticeEmployee) string { return e.Department }, "Eng"), carol, alice, 1},
+		{"FromLess", FromLess(func(a, b BestPracticeEmployee) bool { return a.ID < b.ID }), bob, alice, 1},
+		{"FromLess 相等", FromLess(func(a, b BestPracticeEmployee) bool { return a.Salary < b.Salary }), alice, carol, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sign(tt.cmp(tt.a, tt.b)); got != tt.want {
+				t.Errorf("compare(%s, %s) = %d, 期望 %d", tt.a.Name, tt.b.Name, got, tt.want)
+			}
+			// 交换参数后结果取反
+			if got := sign(tt.cmp(tt.b, tt.a)); got != -tt.want {
+				t.Errorf("compare(%s, %s) = %d, 期望 %d", tt.b.Name, tt.a.Name, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderComparators(t *testing.T) {
+	urgent := BestPracticeOrder{ID: "A1", Amount: 500, Date: day2, Priority: 2, Status: "urgent"}
+	pending := BestPracticeOrder{ID: "A2", Amount: 800, Date: day1, Priority: 1, Status: "pending"}
+	pendingLow := BestPracticeOrder{ID: "A3", Amount: 500, Priority: 1, Status: "pending"}
+	nan := BestPracticeOrder{ID: "A4", Amount: math.NaN()}
+
+	queue := OrderUrgentFirst.Then(OrderByPriority).Then(OrderByAmount.Reverse())
+	tests := []struct {
+		name string
+		cmp  Comparator[BestPracticeOrder]
+		a, b BestPracticeOrder
+		want int
+	}{
+		{"By 金额", OrderByAmount, urgent, pending, -1},
+		{"By 金额相等", OrderByAmount, urgent, pendingLow, 0},
+		{"By NaN 在最前", OrderByAmount, nan, pendingLow, -1},
+		{"ByDesc 金额", ByDesc(func(o BestPracticeOrder) float64 { return o.Amount }), urgent, pending, 1},
+		{"ByDesc NaN 在最后", ByDesc(func(o BestPracticeOrder) float64 { return o.Amount }), nan, pendingLow, 1},
+		{"Reverse 编号", OrderByID.Reverse(), urgent, pending, 1},
+		{"Then urgent 优先", queue, urgent, pending, -1},
+		{"Then 优先级相同按金额降序", queue, pending, pendingLow, -1},
+		{"Then 全部相等", queue, pendingLow, pendingLow, 0},
+		{"日期 早的在前", OrderByDate, pending, urgent, -1},
+		{"日期 零值在后", OrderByDate, pendingLow, urgent, 1},
+		{"日期 都为零值", OrderByDate, pendingLow, nan, 0},
+		{"ZeroLast 优先级零值在后", ZeroLast(func(o BestPracticeOrder) int { return o.Priority }), nan, pending, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sign(tt.cmp(tt.a, tt.b)); got != tt.want {
+				t.Errorf("compare(%s, %s) = %d, 期望 %d", tt.a.ID, tt.b.ID, got, tt.want)
+			}
+			if got := sign(tt.cmp(tt.b, tt.a)); got != -tt.want {
+				t.Errorf("compare(%s, %s) = %d, 期望 %d", tt.b.ID, tt.a.ID, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestNilsFirstNilsLast(t *testing.T) {
+	low := &BestPracticeOrder{ID: "L", Amount: 10}
+	high := &BestPracticeOrder{ID: "H", Amount: 20}
+	tests := []struct {
+		name string
+		cmp  Comparator[*BestPracticeOrder]
+		a, b *BestPracticeOrder
+		want int
+	}{
+		{"NilsFirst nil 在前", NilsFirst(OrderByAmount), nil, low, -1},
+		{"NilsFirst 非 nil 在后", NilsFirst(OrderByAmount), high, nil, 1},
+		{"NilsFirst 都为 nil", NilsFirst(OrderByAmount), nil, nil, 0},
+		{"NilsFirst 非 nil 按元素比较", NilsFirst(OrderByAmount), low, high, -1},
+		{"NilsLast nil 在后", NilsLast(OrderByAmount), nil, low, 1},
+		{"NilsLast 非 nil 在前", NilsLast(OrderByAmount), high, nil, -1},
+		{"NilsLast 都为 nil", NilsLast(OrderByAmount), nil, nil, 0},
+		{"NilsLast 非 nil 按元素比较", NilsLast(OrderByAmount.Reverse()), low, high, 1},
+		{"nil 元素比较器视为相等", NilsLast[BestPracticeOrder](nil), low, high, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sign(tt.cmp(tt.a, tt.b)); got != tt.want {
+				t.Errorf("got %d, 期望 %d", got, tt.want)
+			}
+		})
+	}
+
+	orders := []*BestPracticeOrder{high, nil, low, nil}
+	slices.SortStableFunc(orders, NilsLast(OrderByAmount))
+	if orders[0] != low || orders[1] != high || orders[2] != nil || orders[3] != nil {
+		t.Errorf("NilsLast 排序结果 = %v", orders)
+	}
+}
+
+func TestNilComparator(t *testing.T) {
+	var none Comparator[BestPracticeOrder]
+	a, b := BestPracticeOrder{ID: "A"}, BestPracticeOrder{ID: "B"}
+	if none.Compare(a, b) != 0 {
+		t.Error("nil 比较器应视为所有元素相等")
+	}
+	if none.Reverse() != nil {
+		t.Error("nil 比较器反转后仍为 nil")
+	}
+	if got := sign(none.Then(OrderByID)(a, b)); got != -1 {
+		t.Errorf("nil.Then(OrderByID) = %d, 期望 -1", got)
+	}
+	if got := sign(OrderByID.Then(nil)(a, b)); got != -1 {
+		t.Errorf("OrderByID.Then(nil) = %d, 期望 -1", got)
+	}
+}
+
+// 组合后的比较器配合 SortStableFunc 时，所有级别都相等的元素保持输入顺序
+func TestComparatorStableSort(t *testing.T) {
+	employees := []BestPracticeEmployee{
+		{ID: 1, Name: "A", Department: "Eng", Salary: 9000},
+		{ID: 2, Name: "B", Department: "Sales", Salary: 8000},
+		{ID: 3, Name: "C", Department: "Eng", Salary: 12000},
+		{ID: 4, Name: "D", Department: "Eng", Salary: 9000},
+		{ID: 5, Name: "E", Department: "Sales", Salary: 8000},
+		{ID: 6, Name: "F", Department: "", Salary: 9000},
+		{ID: 7, Name: "G", Department: "Eng", Salary: 9000},
+	}
+	byDept := ZeroLast(func(e BestPracticeEmployee) string { return e.Department }).
+		Then(EmployeeByDepartment).
+		Then(EmployeeBySalary.Reverse())
+
+	sorted := slices.Clone(employees)
+	slices.SortStableFunc(sorted, byDept)
+	want := []int{3, 1, 4, 7, 2, 5, 6}
+	got := make([]int, len(sorted))
+	for i, e := range sorted {
+		got[i] = e.ID
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SortStableFunc 结果 ID = %v, 期望 %v", got, want)
+	}
+
+	// Less 适配 sort.SliceStable，结果与 SortStableFunc 一致
+	legacy := slices.Clone(employees)
+	sort.SliceStable(legacy, byDept.Less(legacy))
+	if !slices.EqualFunc(legacy, sorted, func(a, b BestPracticeEmployee) bool { return a.ID == b.ID }) {
+		t.Error("sort.SliceStable 与 slices.SortStableFunc 的结果不一致")
+	}
+
+	orders := []BestPracticeOrder{
+		{ID: "O1", Status: "pending", Priority: 2},
+		{ID: "O2", Status: "urgent", Priority: 1},
+		{ID: "O3", Status: "pending", Priority: 2},
+		{ID: "O4", Status: "urgent", Priority: 1},
+		{ID: "O5", Status: "completed", Priority: 2},
+	}
+	slices.SortStableFunc(orders, OrderUrgentFirst.Then(OrderByPriority))
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	if want := []string{"O2", "O4", "O1", "O3", "O5"}; !slices.Equal(ids, want) {
+		t.Errorf("订单稳定排序结果 = %v, 期望 %v", ids, want)
+	}
+}
@@ -0,0 +1,585 @@
+// CSV/TSV 排序工具：按多个带类型和方向的键列排序，借助外部排序处理超过内存的文件
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvKeyType 键列的值类型
+type csvKeyType string
+
+const (
+	csvKeyString  csvKeyType = "string"
+	csvKeyNatural csvKeyType = "natural"
+	csvKeyNumber  csvKeyType = "number"
+	csvKeyDate    csvKeyType = "date"
+	csvKeyVersion csvKeyType = "version"
+)
+
+// csvDateLayouts date 类型依次尝试的日期格式
+var csvDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006年1月2日",
+}
+
+// csvSortKey 一个排序键：列、类型和方向
+type csvSortKey struct {
+	spec   string
+	column string
+	index  int // 从 0 开始的列下标，解析表头后确定
+	typ    csvKeyType
+	desc   bool
+}
+
+// csvKeyFlags 可重复的 -k 参数
+type csvKeyFlags []csvSortKey
+
+func (k *csvKeyFlags) String() string {
+	if k == nil {
+		return ""
+	}
+	specs := make([]string, len(*k))
+	for i, key := range *k {
+		specs[i] = key.spec
+	}
+	return strings.Join(specs, " ")
+}
+
+// Set 解析 "列[:类型][:asc|desc]"，列为表头中的列名或从 1 开始的列号
+func (k *csvKeyFlags) Set(spec string) error {
+	parts := strings.Split(spec, ":")
+	key := csvSortKey{spec: spec, column: strings.TrimSpace(parts[0]), index: -1, typ: csvKeyString}
+	if key.column == "" {
+		return fmt.Errorf("排序键 %q 缺少列名或列号", spec)
+	}
+	for _, part := range parts[1:] {
+		switch p := csvKeyType(strings.ToLower(strings.TrimSpace(part))); p {
+		case csvKeyString, csvKeyNatural, csvKeyNumber, csvKeyDate, csvKeyVersion:
+			key.typ = p
+		case "asc":
+			key.desc = false
+		case "desc":
+			key.desc = true
+		default:
+			return fmt.Errorf("排序键 %q 的选项 %q 无效，类型应为 string/natural/number/date/version，方向应为 asc/desc", spec, part)
+		}
+	}
+	*k = append(*k, key)
+	return nil
+}
+
+// csvSortConfig CSV 排序的配置
+type csvSortConfig struct {
+	keys       []csvSortKey
+	comma      rune
+	header     bool
+	stable     bool
+	strict     bool
+	dateLayout string
+	memoryMB   int
+}
+
+// csvRow 一条记录及其预先解析好的键值
+type csvRow struct {
+	fields []string
+	keys   []csvKeyValue
+}
+
+// csvKeyValue 解析后的键值；valid 为 false 表示为空或无法按类型解析，这类值总是排在最后
+type csvKeyValue struct {
+	valid   bool
+	text    string
+	number  float64
+	date    time.Time
+	version versionKey
+}
+
+// versionKey 统一表示 SemVer 和点分数字版本：先比较各数字段，再按 SemVer 规则比较先行版本号
+type versionKey struct {
+	parts      []string
+	prerelease []string
+}
+
+// runCSVSort 排序 files 中的 CSV/TSV 记录（未指定文件时读取 stdin），写入 output 或 stdout
+func runCSVSort(files []string, cfg csvSortConfig, separator, output string, stdin io.Reader, stdout, stderr io.Writer) error {
+	comma, err := parseSeparator(separator, files)
+	if err != nil {
+		return err
+	}
+	cfg.comma = comma
+	if len(cfg.keys) == 0 {
+		// 未指定排序键时按第一列的字符串排序，其余列由非稳定模式的整行比较决胜
+		cfg.keys = []csvSortKey{{spec: "1", column: "1", index: -1, typ: csvKeyString}}
+	}
+
+	inputs, closeInputs, err := openCSVInputs(files, stdin)
+	if err != nil {
+		return err
+	}
+	defer closeInputs()
+
+	out := stdout
+	var outFile *os.File
+	if output != "" {
+		if outFile, err = os.Create(output); err != nil {
+			return fmt.Errorf("创建输出文件失败: %w", err)
+		}
+		defer outFile.Close()
+		out = outFile
+	}
+	stats, err := sortCSV(inputs, out, cfg)
+	if err != nil {
+		return err
+	}
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			return fmt.Errorf("写入输出文件失败: %w", err)
+		}
+	}
+	fmt.Fprintf(stderr, "✅ 已排序 %d 条记录（%d 个归并段，%d 轮归并）\n", stats.Records, stats.Runs, stats.MergePasses)
+	return nil
+}
+
+// parseSeparator 支持 csv、tsv 或单个字符；未指定时根据第一个文件的扩展名判断
+func parseSeparator(separator string, files []string) (rune, error) {
+	if separator == "" {
+		if len(files) > 0 && strings.EqualFold(strings.TrimPrefix(fileExt(files[0]), "."), "tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	}
+	switch strings.ToLower(separator) {
+	case "csv":
+		return ',', nil
+	case "tsv", `\t`:
+		return '\t', nil
+	}
+	if len([]rune(separator)) != 1 || strings.ContainsAny(separator, "\"\r\n") {
+		return 0, fmt.Errorf("分隔符 %q 无效，应为 csv、tsv 或单个字符", separator)
+	}
+	return []rune(separator)[0], nil
+}
+
+func fileExt(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[i:]
+	}
+	return ""
+}
+
+// openCSVInputs 依次打开输入文件，返回的函数关闭所有文件
+func openCSVInputs(files []string, stdin io.Reader) ([]io.Reader, func(), error) {
+	if len(files) == 0 {
+		return []io.Reader{stdin}, func() {}, nil
+	}
+	var readers []io.Reader
+	var closers []io.Closer
+	closeAll := func() {
+		for _, c := range closers {
+			c.Close()
+		}
+	}
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("打开输入文件失败: %w", err)
+		}
+		closers = append(closers, f)
+		readers = append(readers, f)
+	}
+	return readers, closeAll, nil
+}
+
+// sortCSV 读取各输入的表头（如有）后对所有记录做外部排序，表头写在输出的第一行。
+// 各输入末尾缺少换行时补齐，避免前一个文件的最后一条记录与下一个文件的第一条拼在一起
+func sortCSV(inputs []io.Reader, w io.Writer, cfg csvSortConfig) (ExternalSortStats, error) {
+	var header []string
+	bodies := make([]io.Reader, 0, len(inputs))
+	for i, in := range inputs {
+		br := bufio.NewReader(in)
+		if cfg.header {
+			fields, _, err := readCSVRecord(br, cfg.comma)
+			if err != nil && err != io.EOF {
+				return ExternalSortStats{}, fmt.Errorf("读取第 %d 个输入的表头失败: %w", i+1, err)
+			}
+			if header == nil {
+				header = fields
+			} else if fields != nil && !equalStrings(header, fields) {
+				return ExternalSortStats{}, fmt.Errorf("第 %d 个输入的表头 %v 与第一个输入 %v 不一致", i+1, fields, header)
+			}
+		}
+		bodies = append(bodies, &newlineTerminated{r: br})
+	}
+	if err := resolveCSVKeys(cfg.keys, header); err != nil {
+		return ExternalSortStats{}, err
+	}
+
+	out := bufio.NewWriter(w)
+	if header != nil {
+		if err := writeCSVRecord(out, header, cfg.comma); err != nil {
+			return ExternalSortStats{}, err
+		}
+	}
+	stats, err := ExternalSort(io.MultiReader(bodies...), out, csvRowCodec(cfg), csvRowComparator(cfg), ExternalSortOptions{
+		MemoryBudget: cfg.memoryMB << 20,
+	})
+	if err != nil {
+		return stats, err
+	}
+	return stats, out.Flush()
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveCSVKeys 将列名或列号解析为列下标
+func resolveCSVKeys(keys []csvSortKey, header []string) error {
+	for i := range keys {
+		key := &keys[i]
+		if n, err := strconv.Atoi(key.column); err == nil {
+			if n < 1 {
+				return fmt.Errorf("排序键 %q 的列号必须从 1 开始", key.spec)
+			}
+			key.index = n - 1
+			continue
+		}
+		if header == nil {
+			return fmt.Errorf("排序键 %q 使用列名，但输入没有表头（请使用列号或 -header）", key.spec)
+		}
+		key.index = -1
+		for j, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), key.column) {
+				key.index = j
+				break
+			}
+		}
+		if key.index < 0 {
+			return fmt.Errorf("排序键 %q 的列 %q 不存在（表头: %s）", key.spec, key.column, strings.Join(header, ", "))
+		}
+	}
+	return nil
+}
+
+// csvRowCodec 读取记录时解析键值；strict 模式下键值无法解析即报错
+func csvRowCodec(cfg csvSortConfig) RecordCodec[csvRow] {
+	layouts := csvDateLayouts
+	if cfg.dateLayout != "" {
+		layouts = []string{cfg.dateLayout}
+	}
+	return RecordCodec[csvRow]{
+		Read: func(r *bufio.Reader) (csvRow, int, error) {
+			fields, size, err := readCSVRecord(r, cfg.comma)
+			if err != nil {
+				return csvRow{}, 0, err
+			}
+			row := csvRow{fields: fields, keys: make([]csvKeyValue, len(cfg.keys))}
+			for i, key := range cfg.keys {
+				text := ""
+				if key.index < len(fields) {
+					text = fields[key.index]
+				}
+				kv, err := parseCSVKey(text, key.typ, layouts)
+				if err != nil && cfg.strict {
+					return csvRow{}, 0, fmt.Errorf("排序键 %q: %w", key.spec, err)
+				}
+				row.keys[i] = kv
+			}
+			return row, size + 64*len(cfg.keys), nil
+		},
+		Write: func(w *bufio.Writer, row csvRow) error {
+			return writeCSVRecord(w, row.fields, cfg.comma)
+		},
+	}
+}
+
+func parseCSVKey(text string, typ csvKeyType, layouts []string) (csvKeyValue, error) {
+	kv := csvKeyValue{text: text}
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return kv, errors.New("值为空")
+	}
+	switch typ {
+	case csvKeyNumber:
+		digits, ok := stripThousands(trimmed)
+		n, err := strconv.ParseFloat(digits, 64)
+		if !ok || err != nil {
+			return kv, fmt.Errorf("%q 不是数字", text)
+		}
+		kv.number = n
+	case csvKeyDate:
+		var parsed bool
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, trimmed); err == nil {
+				kv.date, parsed = t, true
+				break
+			}
+		}
+		if !parsed {
+			return kv, fmt.Errorf("%q 不是可识别的日期", text)
+		}
+	case csvKeyVersion:
+		v, err := parseVersionKey(trimmed)
+		if err != nil {
+			return kv, err
+		}
+		kv.version = v
+	}
+	kv.valid = true
+	return kv, nil
+}
+
+// stripThousands 去掉数字中的千位分隔符；逗号只能出现在整数部分，且除第一组外每组恰好三位，
+// 否则返回 false，避免把小数逗号 "1,5" 误读为 15
+func stripThousands(s string) (string, bool) {
+	if !strings.Contains(s, ",") {
+		return s, true
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexAny(s, ".eE"); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	sign := ""
+	if intPart != "" && (intPart[0] == '+' || intPart[0] == '-') {
+		sign, intPart = intPart[:1], intPart[1:]
+	}
+	groups := strings.Split(intPart, ",")
+	for i, g := range groups {
+		if !isASCIIDigits(g) || i == 0 && len(g) > 3 || i > 0 && len(g) != 3 {
+			return "", false
+		}
+	}
+	if strings.Contains(frac, ",") {
+		return "", false
+	}
+	return sign + strings.Join(groups, "") + frac, true
+}
+
+// parseVersionKey 先按 SemVer 宽松解析，失败时按点分数字解析（如 "10.0.19041.1"）
+func parseVersionKey(s string) (versionKey, error) {
+	if v, err := ParseSemVerLenient(s); err == nil {
+		return versionKey{
+			parts: []string{
+				strconv.FormatUint(v.Major, 10), strconv.FormatUint(v.Minor, 10), strconv.FormatUint(v.Patch, 10),
+			},
+			prerelease: v.Prerelease,
+		}, nil
+	}
+	parts, err := splitDotted(strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V"))
+	if err != nil {
+		return versionKey{}, err
+	}
+	return versionKey{parts: parts}, nil
+}
+
+func compareVersionKeys(a, b versionKey) int {
+	for i := 0; i < max(len(a.parts), len(b.parts)); i++ {
+		pa, pb := "0", "0"
+		if i < len(a.parts) {
+			pa = a.parts[i]
+		}
+		if i < len(b.parts) {
+			pb = b.parts[i]
+		}
+		if r := cmp.Or(cmp.Compare(len(pa), len(pb)), strings.Compare(pa, pb)); r != 0 {
+			return r
+		}
+	}
+	return SemVer{Prerelease: a.prerelease}.Compare(SemVer{Prerelease: b.prerelease})
+}
+
+// csvRowComparator 依次比较各排序键；无效值不论方向都排在最后。
+// 非 stable 模式下所有键都相等时再按整条记录的字节序决胜，使输出与输入顺序无关
+func csvRowComparator(cfg csvSortConfig) func(a, b csvRow) int {
+	return func(a, b csvRow) int {
+		for i, key := range cfg.keys {
+			ka, kb := a.keys[i], b.keys[i]
+			if ka.valid != kb.valid {
+				return cmp.Compare(btoi(!ka.valid), btoi(!kb.valid))
+			}
+			var r int
+			if ka.valid {
+				switch key.typ {
+				case csvKeyNumber:
+					r = cmp.Compare(ka.number, kb.number)
+				case csvKeyDate:
+					r = ka.date.Compare(kb.date)
+				case csvKeyVersion:
+					r = compareVersionKeys(ka.version, kb.version)
+				case csvKeyNatural:
+					r = NaturalCompare(ka.text, kb.text)
+				default:
+					r = strings.Compare(ka.text, kb.text)
+				}
+			}
+			if key.desc {
+				r = -r
+			}
+			if r != 0 {
+				return r
+			}
+		}
+		if cfg.stable {
+			return 0
+		}
+		for i := 0; i < min(len(a.fields), len(b.fields)); i++ {
+			if r := strings.Compare(a.fields[i], b.fields[i]); r != 0 {
+				return r
+			}
+		}
+		return cmp.Compare(len(a.fields), len(b.fields))
+	}
+}
+
+// readCSVRecord 读取一条记录并返回字段和记录的字节数。
+// TSV 每行一条记录，按制表符原样切分，不处理引号；CSV 中以引号开头的字段可以包含分隔符和换行，
+// 字段中间出现的引号按普通字符处理
+func readCSVRecord(r *bufio.Reader, comma rune) ([]string, int, error) {
+	var text []byte
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			if len(text) == 0 {
+				return nil, 0, io.EOF
+			}
+			return nil, 0, fmt.Errorf("记录 %q 的引号未闭合", text)
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		text = append(text, line...)
+		if comma == '\t' {
+			return strings.Split(trimLineEnd(string(text)), "\t"), len(text), nil
+		}
+		fields, complete, perr := parseCSVRecord(string(text), comma)
+		if perr != nil {
+			return nil, 0, fmt.Errorf("解析记录 %q 失败: %w", text, perr)
+		}
+		if complete {
+			return fields, len(text), nil
+		}
+		if err == io.EOF {
+			return nil, 0, fmt.Errorf("记录 %q 的引号未闭合", text)
+		}
+	}
+}
+
+// parseCSVRecord 解析以换行结尾的一条或多行 CSV 文本；以引号开头的字段尚未闭合时 complete 为 false，需要继续读取下一行
+func parseCSVRecord(s string, comma rune) (fields []string, complete bool, err error) {
+	sep := string(comma)
+	for {
+		if !strings.HasPrefix(s, `"`) {
+			// 不带引号的字段到分隔符或行尾为止，其中的引号原样保留
+			if i := strings.Index(s, sep); i >= 0 && !strings.Contains(s[:i], "\n") {
+				fields = append(fields, s[:i])
+				s = s[i+len(sep):]
+				continue
+			}
+			return append(fields, trimLineEnd(s)), true, nil
+		}
+
+		// 带引号的字段："" 表示一个引号，单独的引号结束字段
+		var field strings.Builder
+		s = s[1:]
+		for {
+			i := strings.IndexByte(s, '"')
+			if i < 0 {
+				return nil, false, nil
+			}
+			field.WriteString(s[:i])
+			s = s[i+1:]
+			if !strings.HasPrefix(s, `"`) {
+				break
+			}
+			field.WriteByte('"')
+			s = s[1:]
+		}
+		fields = append(fields, strings.ReplaceAll(field.String(), "\r\n", "\n"))
+		switch {
+		case strings.HasPrefix(s, sep):
+			s = s[len(sep):]
+		case trimLineEnd(s) == "":
+			return fields, true, nil
+		default:
+			return nil, true, fmt.Errorf("第 %d 个字段的结束引号后出现多余字符", len(fields))
+		}
+	}
+}
+
+// trimLineEnd 去掉行尾的 \n 或 \r\n
+func trimLineEnd(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// writeCSVRecord 写出一条记录。CSV 字段包含分隔符、引号、换行或首尾空白时加引号；
+// TSV 字段不会包含制表符和换行，原样写出
+func writeCSVRecord(w *bufio.Writer, fields []string, comma rune) error {
+	for i, field := range fields {
+		if i > 0 {
+			w.WriteRune(comma)
+		}
+		if comma == '\t' || field == "" || !(strings.ContainsRune(field, comma) || strings.ContainsAny(field, "\"\r\n") ||
+			field[0] == ' ' || field[len(field)-1] == ' ') {
+			w.WriteString(field)
+			continue
+		}
+		w.WriteByte('"')
+		w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		w.WriteByte('"')
+	}
+	return w.WriteByte('\n')
+}
+
+// newlineTerminated 保证非空的读取流以换行结尾
+type newlineTerminated struct {
+	r       io.Reader
+	last    byte
+	pending bool
+	done    bool
+}
+
+func (n *newlineTerminated) Read(p []byte) (int, error) {
+	switch {
+	case n.pending && len(p) > 0:
+		p[0] = '\n'
+		n.pending = false
+		return 1, nil
+	case n.done:
+		return 0, io.EOF
+	}
+	c, err := n.r.Read(p)
+	if c > 0 {
+		n.last = p[c-1]
+	}
+	if err == io.EOF {
+		n.done = true
+		n.pending = n.last != 0 && n.last != '\n'
+		if c > 0 || n.pending {
+			return c, nil
+		}
+	}
+	return c, err
+}
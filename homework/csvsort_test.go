@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVRecord(t *testing.T) {
+	tests := []struct {
+		name  string
+		comma rune
+		input string
+		want  [][]string
+	}{
+		{"简单字段", ',', "a,b,c\n1,2,3\n", [][]string{{"a", "b", "c"}, {"1", "2", "3"}}},
+		{"引号内的分隔符和换行", ',', "\"x,y\",\"多\n行\"\nz,w\n", [][]string{{"x,y", "多\n行"}, {"z", "w"}}},
+		{"转义引号", ',', "\"say \"\"hi\"\"\",b\n", [][]string{{`say "hi"`, "b"}}},
+		{"字段中间的引号按普通字符处理", ',', "TV,55\" screen\nradio,10\n", [][]string{{"TV", `55" screen`}, {"radio", "10"}}},
+		{"CRLF 行尾", ',', "a,b\r\n\"c\r\nd\",e\r\n", [][]string{{"a", "b"}, {"c\nd", "e"}}},
+		{"空行和空字段", ',', "\na,,\n", [][]string{{""}, {"a", "", ""}}},
+		{"末尾缺少换行", ',', "a,b", [][]string{{"a", "b"}}},
+		{"TSV 不处理引号", '\t', "TV\t55\" screen\nradio\t\"10\"\nx\ty\n",
+			[][]string{{"TV", `55" screen`}, {"radio", `"10"`}, {"x", "y"}}},
+		{"自定义分隔符", ';', "a;\"b;c\"\n", [][]string{{"a", "b;c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			var got [][]string
+			total := 0
+			for {
+				fields, size, err := readCSVRecord(r, tt.comma)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("readCSVRecord: %v", err)
+				}
+				got = append(got, fields)
+				total += size
+			}
+			if !slices.EqualFunc(got, tt.want, slices.Equal[[]string]) {
+				t.Errorf("记录 = %q, 期望 %q", got, tt.want)
+			}
+			if total != len(tt.input) {
+				t.Errorf("字节数合计 = %d, 期望 %d", total, len(tt.input))
+			}
+		})
+	}
+}
+
+func TestReadCSVRecordErrors(t *testing.T) {
+	for _, input := range []string{"\"未闭合,a\nb\n", "\"ab\"c,d\n"} {
+		r := bufio.NewReader(strings.NewReader(input))
+		if _, _, err := readCSVRecord(r, ','); err == nil || err == io.EOF {
+			t.Errorf("readCSVRecord(%q) 期望返回解析错误, 实际 %v", input, err)
+		}
+	}
+}
+
+func TestWriteCSVRecord(t *testing.T) {
+	tests := []struct {
+		comma  rune
+		fields []string
+		want   string
+	}{
+		{',', []string{"a", "b,c", `say "hi"`, " pad", "多\n行", ""}, "a,\"b,c\",\"say \"\"hi\"\"\",\" pad\",\"多\n行\",\n"},
+		{'\t', []string{"TV", `55" screen`, " pad", "a,b"}, "TV\t55\" screen\t pad\ta,b\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		if err := writeCSVRecord(w, tt.fields, tt.comma); err != nil {
+			t.Fatal(err)
+		}
+		w.Flush()
+		if buf.String() != tt.want {
+			t.Errorf("writeCSVRecord(%q) = %q, 期望 %q", tt.fields, buf.String(), tt.want)
+		}
+	}
+}
+
+// TSV 中带英寸引号的字段不会吞掉后面的行，输出时原样保留
+func TestRunCSVSortTSVWithQuotes(t *testing.T) {
+	input := "product\tsize\n" +
+		"TV\t55\" screen\n" +
+		"Monitor\t27\" panel\n" +
+		"Cable\t2m\n"
+	var keys csvKeyFlags
+	if err := keys.Set("product"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := csvSortConfig{keys: keys, header: true, memoryMB: 1}
+	var stdout, stderr bytes.Buffer
+	if err := runCSVSort(nil, cfg, "tsv", "", strings.NewReader(input), &stdout, &stderr); err != nil {
+		t.Fatalf("runCSVSort: %v", err)
+	}
+	want := "product\tsize\n" +
+		"Cable\t2m\n" +
+		"Monitor\t27\" panel\n" +
+		"TV\t55\" screen\n"
+	if stdout.String() != want {
+		t.Errorf("输出 =\n%s\n期望\n%s", stdout.String(), want)
+	}
+}
+
+// CSV 中引号开头的字段可以跨行，字段中间的引号按普通字符排序
+func TestRunCSVSortCSVQuotedFields(t *testing.T) {
+	input := "name,note\n" +
+		"b,\"line1\nline2\"\n" +
+		"c,12\" ruler\n" +
+		"a,\"x, y\"\n"
+	var stdout, stderr bytes.Buffer
+	if err := runCSVSort(nil, csvSortConfig{header: true, memoryMB: 1}, "csv", "", strings.NewReader(input), &stdout, &stderr); err != nil {
+		t.Fatalf("runCSVSort: %v", err)
+	}
+	want := "name,note\n" +
+		"a,\"x, y\"\n" +
+		"b,\"line1\nline2\"\n" +
+		"c,\"12\"\" ruler\"\n"
+	if stdout.String() != want {
+		t.Errorf("输出 =\n%s\n期望\n%s", stdout.String(), want)
+	}
+}
+
+// sortCSVColumn 按 specs 排序 input，返回输出中第 col 列（从 0 开始）的值，不含表头
+func sortCSVColumn(t *testing.T, input string, cfg csvSortConfig, col int, specs ...string) []string {
+	t.Helper()
+	var keys csvKeyFlags
+	for _, spec := range specs {
+		if err := keys.Set(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg.keys, cfg.header, cfg.memoryMB = keys, true, 1
+	var stdout, stderr bytes.Buffer
+	if err := runCSVSort(nil, cfg, "csv", "", strings.NewReader(input), &stdout, &stderr); err != nil {
+		t.Fatalf("runCSVSort(%v): %v", specs, err)
+	}
+	return csvColumn(t, stdout.String(), col)[1:]
+}
+
+func csvColumn(t *testing.T, output string, col int) []string {
+	t.Helper()
+	r := bufio.NewReader(strings.NewReader(output))
+	var values []string
+	for {
+		fields, _, err := readCSVRecord(r, ',')
+		if err == io.EOF {
+			return values
+		}
+		if err != nil {
+			t.Fatalf("解析输出失败: %v", err)
+		}
+		values = append(values, fields[col])
+	}
+}
+
+// 各类型的键按值排序；空值和无法解析的值不论方向都排在最后，彼此按整条记录决胜
+func TestRunCSVSortTypedKeys(t *testing.T) {
+	input := "name,qty,when,ver\n" +
+		"a,\"1,200\",2024-01-05,v1.10.0\n" +
+		"b,15,2024/01/02,1.2.0\n" +
+		"c,,2024-01-05 08:00,1.2.0-rc.1\n" +
+		"d,1.5e2,soon,10.0.19041\n" +
+		"e,\"1,5\",2023年12月31日,latest\n" +
+		"f,-3,,v1.9\n"
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"qty:number", []string{"f", "b", "d", "a", "c", "e"}},
+		{"qty:number:desc", []string{"a", "d", "b", "f", "c", "e"}},
+		{"when:date", []string{"e", "b", "a", "c", "d", "f"}},
+		{"when:date:desc", []string{"c", "a", "b", "e", "d", "f"}},
+		{"ver:version", []string{"c", "b", "f", "a", "d", "e"}},
+		{"ver:version:desc", []string{"d", "a", "f", "b", "c", "e"}},
+		{"name:desc", []string{"f", "e", "d", "c", "b", "a"}},
+	}
+	for _, tt := range tests {
+		if got := sortCSVColumn(t, input, csvSortConfig{}, 0, tt.spec); !slices.Equal(got, tt.want) {
+			t.Errorf("-k %s 排序结果 = %v, 期望 %v", tt.spec, got, tt.want)
+		}
+	}
+
+	var keys csvKeyFlags
+	keys.Set("qty:number")
+	var stdout, stderr bytes.Buffer
+	cfg := csvSortConfig{keys: keys, header: true, strict: true, memoryMB: 1}
+	if err := runCSVSort(nil, cfg, "csv", "", strings.NewReader(input), &stdout, &stderr); err == nil {
+		t.Error("-strict 时无法解析的键值应返回错误")
+	}
+}
+
+func TestParseCSVNumberKey(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  float64
+		valid bool
+	}{
+		{"1,234", 1234, true},
+		{"-1,234,567.5", -1234567.5, true},
+		{"+12,345e1", 123450, true},
+		{" 42 ", 42, true},
+		{"1,5", 0, false},
+		{"1,50", 0, false},
+		{"1234,567", 0, false},
+		{",123", 0, false},
+		{"1,,234", 0, false},
+		{"1.234,5", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		kv, err := parseCSVKey(tt.text, csvKeyNumber, nil)
+		if kv.valid != tt.valid || (err == nil) != tt.valid || tt.valid && kv.number != tt.want {
+			t.Errorf("parseCSVKey(%q) = %v, %v, %v; 期望 %v, %v", tt.text, kv.number, kv.valid, err, tt.want, tt.valid)
+		}
+	}
+}
+
+// 多个键依次比较；-stable 时键相同的记录保持输入顺序，否则按整条记录决胜
+func TestRunCSVSortMultipleKeysAndStable(t *testing.T) {
+	input := "dept,salary,name\n" +
+		"ops,100,z\n" +
+		"dev,200,y\n" +
+		"ops,300,x\n" +
+		"dev,200,w\n"
+	tests := []struct {
+		specs  []string
+		stable bool
+		want   []string
+	}{
+		{[]string{"dept", "salary:number:desc"}, false, []string{"w", "y", "x", "z"}},
+		{[]string{"dept", "salary:number:desc"}, true, []string{"y", "w", "x", "z"}},
+		{[]string{"dept"}, false, []string{"w", "y", "z", "x"}},
+		{[]string{"dept"}, true, []string{"y", "w", "z", "x"}},
+		{[]string{"2:number", "1:desc"}, true, []string{"z", "y", "w", "x"}},
+	}
+	for _, tt := range tests {
+		if got := sortCSVColumn(t, input, csvSortConfig{stable: tt.stable}, 2, tt.specs...); !slices.Equal(got, tt.want) {
+			t.Errorf("-k %v stable=%v 排序结果 = %v, 期望 %v", tt.specs, tt.stable, got, tt.want)
+		}
+	}
+}
+
+// 多个文件拼接后一起排序，表头只输出一次；表头不一致时报错
+func TestRunCSVSortMultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first := write("a.csv", "name,qty\nb,2\nd,4") // 末尾缺少换行
+	second := write("b.csv", "Name,qty\n")
+	third := write("c.csv", "name,qty\nc,3\na,1\n")
+	empty := write("d.csv", "")
+	other := write("e.csv", "name,amount\ne,5\n")
+
+	var keys csvKeyFlags
+	keys.Set("qty:number")
+	cfg := csvSortConfig{keys: keys, header: true, memoryMB: 1}
+	var stdout, stderr bytes.Buffer
+	if err := runCSVSort([]string{first, third, empty}, cfg, "", "", nil, &stdout, &stderr); err != nil {
+		t.Fatalf("runCSVSort: %v", err)
+	}
+	if want := "name,qty\na,1\nb,2\nc,3\nd,4\n"; stdout.String() != want {
+		t.Errorf("输出 = %q, 期望 %q", stdout.String(), want)
+	}
+
+	for _, files := range [][]string{{first, other}, {first, third, second}} {
+		stdout.Reset()
+		err := runCSVSort(files, cfg, "", "", nil, &stdout, &stderr)
+		if err == nil || !strings.Contains(err.Error(), "表头") {
+			t.Errorf("表头不一致的文件 %v: 错误 = %v, 期望指出表头不一致", files, err)
+		}
+	}
+}
+
+// 超过 -mem 的输入分段排序后归并，结果与内存中排序一致
+func TestSortCSVExternalMerge(t *testing.T) {
+	var input strings.Builder
+	input.WriteString("id,n\n")
+	want := make([]string, 30000)
+	for i := range want {
+		n := rand.IntN(1000)
+		want[i] = fmt.Sprintf("r%05d,%d", i, n)
+		input.WriteString(want[i] + "\n")
+	}
+	slices.SortStableFunc(want, func(a, b string) int {
+		na, _ := strconv.Atoi(a[strings.IndexByte(a, ',')+1:])
+		nb, _ := strconv.Atoi(b[strings.IndexByte(b, ',')+1:])
+		return cmp.Compare(na, nb)
+	})
+
+	var keys csvKeyFlags
+	keys.Set("n:number")
+	var out bytes.Buffer
+	cfg := csvSortConfig{keys: keys, comma: ',', header: true, stable: true, memoryMB: 1}
+	stats, err := sortCSV([]io.Reader{strings.NewReader(input.String())}, &out, cfg)
+	if err != nil {
+		t.Fatalf("sortCSV: %v", err)
+	}
+	if stats.Runs < 2 || stats.Records != int64(len(want)) {
+		t.Errorf("统计 = %+v, 期望 %d 条记录写入多个归并段", stats, len(want))
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); !slices.Equal(got[1:], want) || got[0] != "id,n" {
+		t.Error("外部排序的结果与内存中稳定排序的结果不一致")
+	}
+}
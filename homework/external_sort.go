@@ -0,0 +1,445 @@
+// 外部归并排序：数据量超过内存时分段排序并写入临时文件，再 k 路归并输出
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RecordCodec 记录在输入、归并段文件和输出中的编码方式
+type RecordCodec[T any] struct {
+	// Read 从 r 读取下一条记录，返回记录及其近似内存占用（字节）；没有更多记录时返回 io.EOF
+	Read func(r *bufio.Reader) (T, int, error)
+	// Write 将一条记录写入 w
+	Write func(w *bufio.Writer, v T) error
+}
+
+// LinesCodec 每行一条记录，记录为去掉换行符的整行文本
+var LinesCodec = RecordCodec[string]{
+	Read: func(r *bufio.Reader) (string, int, error) {
+		line, err := readLine(r)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(line), len(line) + 16, nil
+	},
+	Write: func(w *bufio.Writer, v string) error {
+		if _, err := w.WriteString(v); err != nil {
+			return err
+		}
+		return w.WriteByte('\n')
+	},
+}
+
+// JSONLinesCodec 每行一个 JSON 对象（JSON Lines），适用于员工、订单等结构化导出数据；只含空白的行被忽略
+func JSONLinesCodec[T any]() RecordCodec[T] {
+	return RecordCodec[T]{
+		Read: func(r *bufio.Reader) (T, int, error) {
+			var v T
+			var line []byte
+			// 跳过只含空白的行，例如手工编辑时留下的空行或文件末尾多出的换行
+			for len(bytes.TrimSpace(line)) == 0 {
+				var err error
+				if line, err = readLine(r); err != nil {
+					return v, 0, err
+				}
+			}
+			if err := json.Unmarshal(line, &v); err != nil {
+				return v, 0, fmt.Errorf("解析 JSON 记录失败: %w", err)
+			}
+			// 解码后的结构体通常比 JSON 文本略小，按文本长度估算留有余量
+			return v, len(line), nil
+		},
+		Write: func(w *bufio.Writer, v T) error {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("编码 JSON 记录失败: %w", err)
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			return w.WriteByte('\n')
+		},
+	}
+}
+
+// readLine 读取一行（不含行尾的 \n 或 \r\n），最后一行没有换行符时同样返回；输入结束时返回 io.EOF
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r")), nil
+}
+
+// ExternalSortOptions 外部排序的配置
+type ExternalSortOptions struct {
+	// MemoryBudget 每个归并段在内存中累积的记录大小上限（字节），默认 64MB
+	MemoryBudget int
+	// FanIn 一次归并同时打开的归并段数上限，超过时先多轮归并为更少的段，默认 64
+	FanIn int
+	// WorkDir 存放归并段和进度清单的目录。为空时使用系统临时目录并在结束后删除；
+	// 指定时排序可恢复：出错后以相同输入再次调用会跳过已写入归并段的记录，从中断处继续
+	WorkDir string
+}
+
+func (o ExternalSortOptions) normalize() ExternalSortOptions {
+	if o.MemoryBudget <= 0 {
+		o.MemoryBudget = 64 << 20
+	}
+	if o.FanIn < 2 {
+		o.FanIn = 64
+	}
+	return o
+}
+
+// ExternalSortStats 一次外部排序的统计
+type ExternalSortStats struct {
+	Records     int64
+	Runs        int
+	MergePasses int
+	Resumed     bool
+}
+
+// externalSortManifest 记录外部排序进度，每完成一个归并段或一轮归并后原子地更新
+type externalSortManifest struct {
+	// Consumed 已写入归并段的输入记录数
+	Consumed  int64    `json:"consumed"`
+	InputDone bool     `json:"inputDone"`
+	Runs      []string `json:"runs"`
+	NextRun   int      `json:"nextRun"`
+	Passes    int      `json:"passes"`
+}
+
+const manifestName = "manifest.json"
+
+// ExternalSort 从 r 读取记录，按 MemoryBudget 分段稳定排序后写入临时归并段文件，
+// 再 k 路归并写入 w。cmp 与 slices.SortFunc 的比较函数相同，sort.Slice 风格的 less 可用 FromLess 转换。
+// 出错时删除未写完的临时文件；未指定 WorkDir 时删除全部临时文件，
+// 指定 WorkDir 时保留已完成的归并段和进度清单以便恢复（此时 w 需要重新提供，输出从头写起）
+func ExternalSort[T any](r io.Reader, w io.Writer, codec RecordCodec[T], cmp func(a, b T) int, opts ExternalSortOptions) (stats ExternalSortStats, err error) {
+	opts = opts.normalize()
+	dir := opts.WorkDir
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "extsort-*"); err != nil {
+			return stats, fmt.Errorf("创建临时目录失败: %w", err)
+		}
+		defer os.RemoveAll(dir)
+	} else if err = os.MkdirAll(dir, 0o755); err != nil {
+		return stats, fmt.Errorf("创建工作目录失败: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			removeTempFiles(dir)
+		}
+	}()
+
+	s := &externalSorter[T]{dir: dir, codec: codec, cmp: cmp, opts: opts}
+	if err = s.loadManifest(); err != nil {
+		return stats, err
+	}
+	stats.Resumed = s.m.Consumed > 0 || len(s.m.Runs) > 0
+
+	if !s.m.InputDone {
+		if err = s.spill(r); err != nil {
+			return stats, err
+		}
+	}
+	stats.Runs = s.m.NextRun
+	for len(s.m.Runs) > opts.FanIn {
+		if err = s.mergePass(); err != nil {
+			return stats, err
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	if stats.Records, err = s.mergeRuns(s.m.Runs, out); err != nil {
+		return stats, err
+	}
+	if err = out.Flush(); err != nil {
+		return stats, fmt.Errorf("写出排序结果失败: %w", err)
+	}
+	stats.MergePasses = s.m.Passes + 1
+
+	for _, run := range s.m.Runs {
+		os.Remove(filepath.Join(dir, run))
+	}
+	os.Remove(filepath.Join(dir, manifestName))
+	return stats, nil
+}
+
+type externalSorter[T any] struct {
+	dir   string
+	codec RecordCodec[T]
+	cmp   func(a, b T) int
+	opts  ExternalSortOptions
+	m     externalSortManifest
+}
+
+func (s *externalSorter[T]) loadManifest() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, manifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取进度清单失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.m); err != nil {
+		return fmt.Errorf("解析进度清单失败: %w", err)
+	}
+	return nil
+}
+
+// saveManifest 先写临时文件再改名，保证清单始终完整
+func (s *externalSorter[T]) saveManifest() error {
+	data, err := json.Marshal(s.m)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, manifestName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("写入进度清单失败: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("写入进度清单失败: %w", err)
+	}
+	return nil
+}
+
+// spill 读取输入，每累积 MemoryBudget 字节的记录就排序并写出一个归并段
+func (s *externalSorter[T]) spill(r io.Reader) error {
+	in := bufio.NewReader(r)
+	// 恢复时跳过上次已写入归并段的记录
+	for i := int64(0); i < s.m.Consumed; i++ {
+		if _, _, err := s.codec.Read(in); err != nil {
+			return fmt.Errorf("恢复时跳过第 %d 条已处理记录失败（输入与上次不一致？）: %w", i+1, err)
+		}
+	}
+
+	var buf []T
+	used := 0
+	for {
+		v, size, err := s.codec.Read(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取第 %d 条记录失败: %w", s.m.Consumed+int64(len(buf))+1, err)
+		}
+		buf = append(buf, v)
+		used += size
+		if used >= s.opts.MemoryBudget {
+			if err := s.writeRun(buf); err != nil {
+				return err
+			}
+			clear(buf)
+			buf, used = buf[:0], 0
+		}
+	}
+	if len(buf) > 0 {
+		if err := s.writeRun(buf); err != nil {
+			return err
+		}
+	}
+	s.m.InputDone = true
+	return s.saveManifest()
+}
+
+// writeRun 将一段记录稳定排序后写入新的归并段，并记入进度清单
+func (s *externalSorter[T]) writeRun(records []T) error {
+	slices.SortStableFunc(records, s.cmp)
+	name := fmt.Sprintf("run-%06d", s.m.NextRun)
+	err := s.createFile(name, func(w *bufio.Writer) error {
+		for _, v := range records {
+			if err := s.codec.Write(w, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("写入归并段 %s 失败: %w", name, err)
+	}
+	s.m.Runs = append(s.m.Runs, name)
+	s.m.NextRun++
+	s.m.Consumed += int64(len(records))
+	return s.saveManifest()
+}
+
+// createFile 通过临时文件写入 name，写完后改名，避免留下不完整的归并段
+func (s *externalSorter[T]) createFile(name string, write func(w *bufio.Writer) error) error {
+	path := filepath.Join(s.dir, name)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// mergePass 将最前面的 FanIn 个归并段合并为一个，保持段的先后顺序以维持稳定性
+func (s *externalSorter[T]) mergePass() error {
+	group := s.m.Runs[:s.opts.FanIn]
+	name := fmt.Sprintf("run-%06d", s.m.NextRun)
+	err := s.createFile(name, func(w *bufio.Writer) error {
+		_, err := s.mergeRuns(group, w)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("归并到 %s 失败: %w", name, err)
+	}
+
+	merged := slices.Clone(group)
+	s.m.Runs = append([]string{name}, s.m.Runs[s.opts.FanIn:]...)
+	s.m.NextRun++
+	s.m.Passes++
+	if err := s.saveManifest(); err != nil {
+		return err
+	}
+	for _, run := range merged {
+		os.Remove(filepath.Join(s.dir, run))
+	}
+	return nil
+}
+
+// mergeRuns k 路归并若干归并段写入 w，返回写出的记录数
+func (s *externalSorter[T]) mergeRuns(runs []string, w *bufio.Writer) (int64, error) {
+	readers := make([]*bufio.Reader, len(runs))
+	for i, run := range runs {
+		f, err := os.Open(filepath.Join(s.dir, run))
+		if err != nil {
+			return 0, fmt.Errorf("打开归并段失败: %w", err)
+		}
+		defer f.Close()
+		readers[i] = bufio.NewReader(f)
+	}
+
+	h := newMergeHeap(s.cmp)
+	for i, r := range readers {
+		v, _, err := s.codec.Read(r)
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("读取归并段 %s 失败: %w", runs[i], err)
+		}
+		h.push(v, i)
+	}
+
+	var written int64
+	for h.len() > 0 {
+		v, src := h.top()
+		if err := s.codec.Write(w, v); err != nil {
+			return written, fmt.Errorf("写出记录失败: %w", err)
+		}
+		written++
+		next, _, err := s.codec.Read(readers[src])
+		switch {
+		case err == io.EOF:
+			h.pop()
+		case err != nil:
+			return written, fmt.Errorf("读取归并段 %s 失败: %w", runs[src], err)
+		default:
+			h.replaceTop(next, src)
+		}
+	}
+	return written, nil
+}
+
+// removeTempFiles 删除目录中未写完的临时文件
+func removeTempFiles(dir string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	for _, path := range matches {
+		os.Remove(path)
+	}
+}
+
+// demonstrateExternalSort 在很小的内存预算下对 JSON Lines 格式的订单导出进行外部排序，
+// 并演示输入中断后的恢复
+func demonstrateExternalSort() {
+	fmt.Println("  外部排序示例 (2万条 JSON Lines 订单, 内存预算 256KB):")
+	var input bytes.Buffer
+	statuses := []string{"pending", "processing", "urgent", "completed"}
+	for i := 0; i < 20_000; i++ {
+		order := BestPracticeOrder{
+			ID:       fmt.Sprintf("ORD%05d", i),
+			Amount:   float64(rand.IntN(100_000)) / 10,
+			Date:     time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(rand.IntN(720)) * time.Hour),
+			Priority: rand.IntN(3) + 1,
+			Status:   statuses[rand.IntN(len(statuses))],
+		}
+		data, _ := json.Marshal(order)
+		input.Write(data)
+		input.WriteByte('\n')
+	}
+	codec := JSONLinesCodec[BestPracticeOrder]()
+	byAmount := OrderByAmount.Reverse().Then(OrderByID)
+	opts := ExternalSortOptions{MemoryBudget: 256 << 10, FanIn: 4}
+
+	workDir, err := os.MkdirTemp("", "extsort-demo-*")
+	if err != nil {
+		fmt.Printf("     创建工作目录失败: %v\n", err)
+		return
+	}
+	defer os.RemoveAll(workDir)
+	opts.WorkDir = workDir
+
+	// 第一次：输入流传到一半时连接中断
+	var output strings.Builder
+	broken, pw := io.Pipe()
+	go func() {
+		pw.Write(input.Bytes()[:input.Len()/2])
+		pw.CloseWithError(errors.New("模拟的网络中断"))
+	}()
+	_, err = ExternalSort(broken, &output, codec, byAmount, opts)
+	entries, _ := os.ReadDir(workDir)
+	fmt.Printf("     第一次排序失败: %v (工作目录保留 %d 个文件)\n", err, len(entries))
+
+	// 第二次：以相同输入恢复
+	output.Reset()
+	stats, err := ExternalSort(bytes.NewReader(input.Bytes()), &output, codec, byAmount, opts)
+	if err != nil {
+		fmt.Printf("     恢复排序失败: %v\n", err)
+		return
+	}
+	entries, _ = os.ReadDir(workDir)
+	fmt.Printf("     恢复排序完成: %d 条记录, %d 个归并段, %d 轮归并, 恢复: %v, 剩余临时文件: %d\n",
+		stats.Records, stats.Runs, stats.MergePasses, stats.Resumed, len(entries))
+
+	var sorted []BestPracticeOrder
+	in := bufio.NewReader(strings.NewReader(output.String()))
+	for {
+		order, _, err := codec.Read(in)
+		if err != nil {
+			break
+		}
+		sorted = append(sorted, order)
+	}
+	fmt.Printf("     输出有序: %v, 金额最高的订单:\n", slices.IsSortedFunc(sorted, byAmount))
+	printBestPracticeOrders(sorted[:min(3, len(sorted))])
+}
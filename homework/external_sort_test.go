@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var errInterrupted = errors.New("模拟的网络中断")
+
+// failingReader 读取 limit 字节后返回错误，用于模拟中途失败的输入
+type failingReader struct {
+	r     io.Reader
+	limit int
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if f.limit <= 0 {
+		return 0, errInterrupted
+	}
+	n, err := f.r.Read(p[:min(len(p), f.limit)])
+	f.limit -= n
+	return n, err
+}
+
+// encodeOrders 将订单编码为 JSON Lines
+func encodeOrders(t *testing.T, orders []BestPracticeOrder) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, o := range orders {
+		data, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func decodeOrders(t *testing.T, data string) []BestPracticeOrder {
+	t.Helper()
+	codec := JSONLinesCodec[BestPracticeOrder]()
+	r := bufio.NewReader(strings.NewReader(data))
+	var orders []BestPracticeOrder
+	for {
+		o, _, err := codec.Read(r)
+		if err == io.EOF {
+			return orders
+		}
+		if err != nil {
+			t.Fatalf("解码输出失败: %v", err)
+		}
+		orders = append(orders, o)
+	}
+}
+
+func orderIDs(orders []BestPracticeOrder) []string {
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func TestExternalSortMatchesSortStable(t *testing.T) {
+	orders := randomOrders(5000)
+	// 优先级只有 3 种取值，相等的记录分散在各个归并段中，检验跨段归并时保持输入顺序
+	byPriority := OrderByPriority
+	var output strings.Builder
+	stats, err := ExternalSort(bytes.NewReader(encodeOrders(t, orders)), &output,
+		JSONLinesCodec[BestPracticeOrder](), byPriority, ExternalSortOptions{MemoryBudget: 32 << 10, FanIn: 3})
+	if err != nil {
+		t.Fatalf("ExternalSort: %v", err)
+	}
+	if stats.Records != int64(len(orders)) || stats.Runs < 2 || stats.MergePasses < 2 || stats.Resumed {
+		t.Errorf("统计 = %+v, 期望 %d 条记录、多个归并段、多轮归并且未恢复", stats, len(orders))
+	}
+	want := slices.Clone(orders)
+	slices.SortStableFunc(want, byPriority)
+	if got := decodeOrders(t, output.String()); !slices.Equal(orderIDs(got), orderIDs(want)) {
+		t.Error("外部排序结果与 slices.SortStableFunc 不一致")
+	}
+}
+
+// 只含空白的行被跳过，不计入记录数
+func TestJSONLinesCodecSkipsBlankLines(t *testing.T) {
+	orders := randomOrders(3)
+	lines := strings.Split(strings.TrimSuffix(string(encodeOrders(t, orders)), "\n"), "\n")
+	input := "\n  \t\n" + lines[0] + "\r\n\r\n" + lines[1] + "\n \n\n" + lines[2] + "\n\n   "
+	if got := decodeOrders(t, input); !slices.Equal(orderIDs(got), orderIDs(orders)) {
+		t.Errorf("解码结果 = %v, 期望 %v", orderIDs(got), orderIDs(orders))
+	}
+
+	var output strings.Builder
+	stats, err := ExternalSort(strings.NewReader(input), &output, JSONLinesCodec[BestPracticeOrder](), OrderByID, ExternalSortOptions{})
+	if err != nil || stats.Records != 3 {
+		t.Errorf("ExternalSort = %+v, %v; 期望 3 条记录", stats, err)
+	}
+	if _, _, err := JSONLinesCodec[BestPracticeOrder]().Read(bufio.NewReader(strings.NewReader("{\"ID\": \n"))); err == nil || err == io.EOF {
+		t.Errorf("不完整的 JSON 错误 = %v, 期望解析错误", err)
+	}
+}
+
+// 输入中途失败后保留已完成的归并段和清单，以相同输入再次调用时从中断处继续，记录不重复也不丢失
+func TestExternalSortResumesFromManifest(t *testing.T) {
+	orders := randomOrders(5000)
+	input := encodeOrders(t, orders)
+	byAmount := OrderByAmount.Reverse().Then(OrderByID)
+	codec := JSONLinesCodec[BestPracticeOrder]()
+	workDir := t.TempDir()
+	opts := ExternalSortOptions{MemoryBudget: 32 << 10, FanIn: 3, WorkDir: workDir}
+
+	var output strings.Builder
+	broken := &failingReader{r: bytes.NewReader(input), limit: len(input) * 2 / 3}
+	if _, err := ExternalSort(broken, &output, codec, byAmount, opts); !errors.Is(err, errInterrupted) {
+		t.Fatalf("第一次排序的错误 = %v, 期望 %v", err, errInterrupted)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, manifestName))
+	if err != nil {
+		t.Fatalf("失败后应保留进度清单: %v", err)
+	}
+	var m externalSortManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Consumed == 0 || m.InputDone || len(m.Runs) == 0 {
+		t.Fatalf("清单 = %+v, 期望已写入部分归并段且输入未读完", m)
+	}
+	for _, run := range m.Runs {
+		if _, err := os.Stat(filepath.Join(workDir, run)); err != nil {
+			t.Errorf("清单中的归并段 %s 不存在: %v", run, err)
+		}
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(workDir, "*.tmp")); len(tmp) > 0 {
+		t.Errorf("失败后残留未写完的临时文件: %v", tmp)
+	}
+
+	output.Reset()
+	stats, err := ExternalSort(bytes.NewReader(input), &output, codec, byAmount, opts)
+	if err != nil {
+		t.Fatalf("恢复排序失败: %v", err)
+	}
+	if !stats.Resumed || stats.Records != int64(len(orders)) {
+		t.Errorf("统计 = %+v, 期望从清单恢复且共 %d 条记录", stats, len(orders))
+	}
+	want := slices.Clone(orders)
+	slices.SortStableFunc(want, byAmount)
+	if got := decodeOrders(t, output.String()); !slices.Equal(orderIDs(got), orderIDs(want)) {
+		t.Error("恢复后的排序结果与完整排序不一致")
+	}
+	if entries, _ := os.ReadDir(workDir); len(entries) != 0 {
+		t.Errorf("排序完成后工作目录仍有 %d 个文件", len(entries))
+	}
+}
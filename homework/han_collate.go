@@ -0,0 +1,269 @@
+// 汉字排序：按拼音或笔画数比较中文姓名等字符串
+package main
+
+import (
+	"bufio"
+	"cmp"
+	_ "embed"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed han_table.txt
+var embeddedHanTable string
+
+// HanOrder 汉字的排序依据
+type HanOrder int
+
+const (
+	// HanByPinyin 按拼音字母序，同音字按声调、再按笔画数
+	HanByPinyin HanOrder = iota
+	// HanByStrokes 按笔画数，笔画相同按拼音
+	HanByStrokes
+)
+
+// hanEntry 单个汉字的排序信息
+type hanEntry struct {
+	pinyin  string
+	tone    int
+	strokes int
+}
+
+// HanCollator 汉字排序比较器
+//
+// 逐字比较：非汉字字符（字母、数字、符号）排在汉字之前，彼此按忽略大小写的码点比较；
+// 汉字按拼音或笔画数比较；排序表未收录的汉字排在已收录汉字之后，彼此按码点比较。
+// 忽略大小写后完全相同时，以第一处大小写差异决胜（大写在前），仍相同时按原始字节序决胜
+type HanCollator struct {
+	order HanOrder
+
+	mu    sync.RWMutex
+	table map[rune]hanEntry
+}
+
+// NewHanCollator 创建使用内置排序表的汉字比较器
+func NewHanCollator(order HanOrder) *HanCollator {
+	c := &HanCollator{order: order, table: make(map[rune]hanEntry)}
+	if err := c.Extend(strings.NewReader(embeddedHanTable)); err != nil {
+		panic(fmt.Sprintf("内置汉字排序表无效: %v", err))
+	}
+	return c
+}
+
+// Extend 从 r 读取补充的排序表并覆盖已有条目，用于补充生僻字或指定多音姓氏的读音（如 "曾 zeng1 12"）。
+// 每行格式为 "字 拼音声调 笔画数"，以 # 开头的行和空行被忽略
+func (c *HanCollator) Extend(r io.Reader) error {
+	entries := make(map[rune]hanEntry)
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || utf8.RuneCountInString(fields[0]) != 1 {
+			return fmt.Errorf("第 %d 行格式错误，应为 \"字 拼音声调 笔画数\": %q", lineNo, line)
+		}
+		char, _ := utf8.DecodeRuneInString(fields[0])
+		syllable := fields[1]
+		tone, err := strconv.Atoi(syllable[len(syllable)-1:])
+		if err != nil || tone < 1 || tone > 5 {
+			return fmt.Errorf("第 %d 行拼音缺少 1-5 的声调数字: %q", lineNo, syllable)
+		}
+		strokes, err := strconv.Atoi(fields[2])
+		if err != nil || strokes <= 0 {
+			return fmt.Errorf("第 %d 行笔画数无效: %q", lineNo, fields[2])
+		}
+		entries[char] = hanEntry{pinyin: syllable[:len(syllable)-1], tone: tone, strokes: strokes}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取排序表失败: %w", err)
+	}
+
+	c.mu.Lock()
+	for char, entry := range entries {
+		c.table[char] = entry
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// 字符类别，数值小的排在前面
+const (
+	charClassOther = iota
+	charClassHan
+	charClassUnknownHan
+)
+
+func (c *HanCollator) classify(r rune) (int, hanEntry) {
+	if !unicode.Is(unicode.Han, r) {
+		return charClassOther, hanEntry{}
+	}
+	if entry, ok := c.table[r]; ok {
+		return charClassHan, entry
+	}
+	return charClassUnknownHan, hanEntry{}
+}
+
+// Compare 三路比较 a 和 b
+func (c *HanCollator) Compare(a, b string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	origA, origB := a, b
+	tie := 0
+	for a != "" && b != "" {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		a, b = a[sizeA:], b[sizeB:]
+		if ra == rb {
+			continue
+		}
+
+		classA, entryA := c.classify(ra)
+		classB, entryB := c.classify(rb)
+		if classA != classB {
+			return cmp.Compare(classA, classB)
+		}
+		switch classA {
+		case charClassHan:
+			if r := cmp.Or(c.compareEntries(entryA, entryB), cmp.Compare(ra, rb)); r != 0 {
+				return r
+			}
+		case charClassUnknownHan:
+			return cmp.Compare(ra, rb)
+		default:
+			// 只有大小写不同时推迟到整个字符串比较完再决胜，"alex" 排在 "Alice" 之前
+			if la, lb := unicode.ToLower(ra), unicode.ToLower(rb); la != lb {
+				return cmp.Compare(la, lb)
+			}
+			if tie == 0 {
+				tie = cmp.Compare(ra, rb)
+			}
+		}
+	}
+	switch {
+	case a != "":
+		return 1
+	case b != "":
+		return -1
+	case tie != 0:
+		return tie
+	default:
+		return strings.Compare(origA, origB)
+	}
+}
+
+func (c *HanCollator) compareEntries(a, b hanEntry) int {
+	byPinyin := cmp.Or(strings.Compare(a.pinyin, b.pinyin), cmp.Compare(a.tone, b.tone))
+	byStrokes := cmp.Compare(a.strokes, b.strokes)
+	if c.order == HanByStrokes {
+		return cmp.Or(byStrokes, byPinyin)
+	}
+	return cmp.Or(byPinyin, byStrokes)
+}
+
+// Pinyin 返回 s 的拼音（带数字声调，音节之间以空格分隔），未收录的字符原样保留，便于报表展示或排查排序结果
+func (c *HanCollator) Pinyin(s string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var b strings.Builder
+	prevSyllable := false
+	for i, r := range s {
+		entry, ok := c.table[r]
+		if i > 0 && (ok || prevSyllable) {
+			b.WriteByte(' ')
+		}
+		if ok {
+			b.WriteString(entry.pinyin)
+			b.WriteString(strconv.Itoa(entry.tone))
+		} else {
+			b.WriteRune(r)
+		}
+		prevSyllable = ok
+	}
+	return b.String()
+}
+
+// Strokes 返回 s 中已收录汉字的笔画总数
+func (c *HanCollator) Strokes(s string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	total := 0
+	for _, r := range s {
+		total += c.table[r].strokes
+	}
+	return total
+}
+
+// Uncovered 返回 s 中排序表未收录的汉字（去重，按出现顺序），这些字按码点排在已收录汉字之后，
+// Pinyin 和 Strokes 也无法给出它们的拼音和笔画
+func (c *HanCollator) Uncovered(s string) []rune {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var missing []rune
+	for _, r := range s {
+		if class, _ := c.classify(r); class == charClassUnknownHan && !slices.Contains(missing, r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+var (
+	// PinyinCollator 按拼音排序的默认比较器
+	PinyinCollator = NewHanCollator(HanByPinyin)
+	// StrokeCollator 按笔画排序的默认比较器
+	StrokeCollator = NewHanCollator(HanByStrokes)
+)
+
+// 按姓名、部门的拼音或笔画排序的员工比较器
+var (
+	EmployeeByNamePinyin       = ByFunc(func(e BestPracticeEmployee) string { return e.Name }, PinyinCollator.Compare)
+	EmployeeByNameStrokes      = ByFunc(func(e BestPracticeEmployee) string { return e.Name }, StrokeCollator.Compare)
+	EmployeeByDepartmentPinyin = ByFunc(func(e BestPracticeEmployee) string { return e.Department }, PinyinCollator.Compare)
+)
+
+// demonstrateHanCollation 对比中文姓名的字节序、拼音序和笔画序
+func demonstrateHanCollation() {
+	fmt.Println("4.1 中文姓名排序:")
+	fmt.Println()
+	names := []string{"张三", "李四", "王五", "赵六", "钱七", "孙八", "欧阳锋", "张伟", "Alice", "陈静", "王喆"}
+
+	byteOrder := slices.Clone(names)
+	slices.Sort(byteOrder)
+	pinyinOrder := slices.Clone(names)
+	slices.SortFunc(pinyinOrder, PinyinCollator.Compare)
+	strokeOrder := slices.Clone(names)
+	slices.SortFunc(strokeOrder, StrokeCollator.Compare)
+
+	fmt.Printf("  字节序: %s\n", strings.Join(byteOrder, ", "))
+	fmt.Println("  拼音序:")
+	for _, name := range pinyinOrder {
+		fmt.Printf("    %s (%s)\n", name, PinyinCollator.Pinyin(name))
+	}
+	fmt.Println("  笔画序:")
+	for _, name := range strokeOrder {
+		first, _ := utf8.DecodeRuneInString(name)
+		fmt.Printf("    %s (首字 %d 画)\n", name, StrokeCollator.Strokes(string(first)))
+	}
+
+	var uncovered []rune
+	for _, name := range names {
+		for _, r := range PinyinCollator.Uncovered(name) {
+			if !slices.Contains(uncovered, r) {
+				uncovered = append(uncovered, r)
+			}
+		}
+	}
+	if len(uncovered) > 0 {
+		fmt.Printf("  ⚠️  排序表未收录: %s（按码点排在已收录汉字之后，可通过 HanCollator.Extend 补充）\n", string(uncovered))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// 内置排序表至少覆盖 GB2312 一级汉字，且没有重复条目
+func TestEmbeddedHanTableCoverage(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(embeddedHanTable, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		char := strings.Fields(line)[0]
+		if seen[char] {
+			t.Errorf("排序表中 %s 重复出现", char)
+		}
+		seen[char] = true
+	}
+	if len(seen) < 3755 {
+		t.Errorf("排序表收录 %d 字, 期望至少覆盖 GB2312 一级汉字 3755 字", len(seen))
+	}
+	// GB2312 一级汉字的首尾及常见姓名用字
+	for _, r := range "啊座锋欧阳诸葛司马慕容尉迟" {
+		if missing := PinyinCollator.Uncovered(string(r)); len(missing) > 0 {
+			t.Errorf("排序表未收录 %c", r)
+		}
+	}
+}
+
+func TestHanCollatorPinyinAndStrokes(t *testing.T) {
+	tests := []struct {
+		name    string
+		pinyin  string
+		strokes int
+	}{
+		{"欧阳锋", "ou1 yang2 feng1", 8 + 6 + 12},
+		{"黄蓉", "huang2 rong2", 11 + 13},
+		{"郭靖", "guo1 jing4", 10 + 13},
+		{"曾任", "zeng1 ren2", 12 + 6},
+		{"邓运", "deng4 yun4", 4 + 7},
+		{"王喆", "wang2 喆", 4},
+		// 姓氏用字取姓氏读音
+		{"查仇单盖解区", "zha1 qiu2 shan4 ge3 xie4 ou1", 9 + 4 + 8 + 11 + 13 + 4},
+	}
+	for _, tt := range tests {
+		if got := PinyinCollator.Pinyin(tt.name); got != tt.pinyin {
+			t.Errorf("Pinyin(%q) = %q, 期望 %q", tt.name, got, tt.pinyin)
+		}
+		if got := StrokeCollator.Strokes(tt.name); got != tt.strokes {
+			t.Errorf("Strokes(%q) = %d, 期望 %d", tt.name, got, tt.strokes)
+		}
+	}
+}
+
+func TestHanCollatorUncovered(t *testing.T) {
+	if got := PinyinCollator.Uncovered("王喆 Alice 喆堃"); string(got) != "喆堃" {
+		t.Errorf("Uncovered = %q, 期望 %q", string(got), "喆堃")
+	}
+	if got := PinyinCollator.Uncovered("欧阳锋"); len(got) != 0 {
+		t.Errorf("Uncovered(欧阳锋) = %q, 期望为空", string(got))
+	}
+
+	c := NewHanCollator(HanByPinyin)
+	if err := c.Extend(strings.NewReader("喆 zhe2 12\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Uncovered("王喆"); len(got) != 0 {
+		t.Errorf("Extend 后 Uncovered = %q, 期望为空", string(got))
+	}
+}
+
+func TestHanCollatorOrder(t *testing.T) {
+	names := []string{"欧阳锋", "王喆", "Alice", "陈静", "王五", "黄蓉", "郭靖"}
+
+	byPinyin := slices.Clone(names)
+	slices.SortFunc(byPinyin, PinyinCollator.Compare)
+	if want := []string{"Alice", "陈静", "郭靖", "黄蓉", "欧阳锋", "王五", "王喆"}; !slices.Equal(byPinyin, want) {
+		t.Errorf("拼音序 = %v, 期望 %v", byPinyin, want)
+	}
+
+	byStrokes := slices.Clone(names)
+	slices.SortFunc(byStrokes, StrokeCollator.Compare)
+	if want := []string{"Alice", "王五", "王喆", "陈静", "欧阳锋", "郭靖", "黄蓉"}; !slices.Equal(byStrokes, want) {
+		t.Errorf("笔画序 = %v, 期望 %v", byStrokes, want)
+	}
+}
+
+// 大小写只在忽略大小写后完全相同时才决胜
+func TestHanCollatorCase(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"alex", "Alice", -1},
+		{"Alice", "alex", 1},
+		{"bob", "Alice", 1},
+		{"Alice", "alice", -1},
+		{"aB", "Ab", 1}, // 以第一处大小写差异决胜
+		{"alice", "Alice2", -1},
+		{"Alice王", "alice陈", 1},
+		{"alice", "alice", 0},
+	}
+	for _, tt := range tests {
+		if got := sign(PinyinCollator.Compare(tt.a, tt.b)); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, 期望 %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	names := []string{"alice", "Bob", "alex", "Alice", "ALEX"}
+	slices.SortFunc(names, PinyinCollator.Compare)
+	if want := []string{"ALEX", "alex", "Alice", "alice", "Bob"}; !slices.Equal(names, want) {
+		t.Errorf("排序结果 = %v, 期望 %v", names, want)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+// benchmarkOnCopy 每轮在 data 的副本上运行 fn，复制时间不计入结果
+func benchmarkOnCopy[T any](b *testing.B, data []T, fn func([]T)) {
+	b.ReportAllocs()
+	buf := make([]T, len(data))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		copy(buf, data)
+		b.StartTimer()
+		fn(buf)
+	}
+}
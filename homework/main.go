@@ -0,0 +1,64 @@
+// 排序作业的命令行入口：CSV/TSV 排序工具和排序最佳实践演示
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+const csvSortUsage = `用法: homework [选项] [文件...]
+
+按一个或多个键列排序 CSV/TSV 文件，未指定文件时读取标准输入，结果写到标准输出。
+多个文件按顺序拼接后一起排序。超过 -mem 的输入会分段排序后在临时目录中归并，
+因此可以处理大于内存的文件。
+
+排序键 -k 的格式为 列[:类型][:asc|desc]，可重复指定，前面的键优先:
+  列    表头中的列名（忽略大小写）或从 1 开始的列号
+  类型  string（默认，按字节序）、natural（自然排序，file2 < file10）、
+        number（可带千位分隔符，如 1,234.5）、date、version（SemVer 或点分数字版本）
+  空值及无法按类型解析的值不论方向都排在最后，-strict 时报错退出
+
+示例:
+  homework -k department -k salary:number:desc employees.csv
+  homework -sep tsv -header=false -k 3:date -k 1:natural -stable data.tsv
+
+选项:
+`
+
+func main() {
+	var cfg csvSortConfig
+	var keys csvKeyFlags
+	demo := flag.Bool("demo", false, "运行排序最佳实践演示后退出")
+	flag.StringVar(&orderRulesPath, "order-rules", "", "演示中订单优先级规则的 JSON 文件，默认使用内置规则")
+	flag.Var(&keys, "k", "排序键，格式为 列[:类型][:asc|desc]，可重复指定")
+	separator := flag.String("sep", "", "分隔符: csv、tsv 或单个字符，默认根据文件扩展名判断")
+	flag.BoolVar(&cfg.header, "header", true, "第一行是表头：保持在输出首行，且可在 -k 中按列名引用")
+	flag.BoolVar(&cfg.stable, "stable", false, "稳定排序：键相同的记录保持输入顺序，否则按整条记录决胜")
+	flag.BoolVar(&cfg.strict, "strict", false, "排序键为空或无法按类型解析时报错")
+	flag.StringVar(&cfg.dateLayout, "date-layout", "", "date 类型的日期格式（Go 时间格式），默认尝试常见格式")
+	flag.IntVar(&cfg.memoryMB, "mem", 64, "内存中排序的数据量上限（MB），超过时使用外部排序")
+	output := flag.String("o", "", "输出文件，默认写到标准输出")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), csvSortUsage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *demo {
+		DemonstrateSortingBestPractices()
+		return
+	}
+
+	if flag.NArg() == 0 {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+	cfg.keys = keys
+	if err := runCSVSort(flag.Args(), cfg, *separator, *output, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
+	}
+}
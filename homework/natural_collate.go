@@ -0,0 +1,182 @@
+// Unicode 自然排序：数字部分按数值比较，支持任意长度的数字、各书写系统的十进制数字以及大小写/全半角折叠
+package main
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NaturalOptions 自然排序的折叠选项
+type NaturalOptions struct {
+	// FoldCase 忽略大小写（Unicode 简单大小写折叠），例如 "File2" 与 "file2" 视为相同
+	FoldCase bool
+	// FoldWidth 全角字符按半角处理，例如 "ｆｉｌｅ" 与 "file" 视为相同
+	FoldWidth bool
+}
+
+// NaturalCollator 自然排序比较器
+//
+// 字符串被切分为数字段和非数字段逐段比较：数字段（任意书写系统的十进制数字，如 "12"、"１２"、"١٢"）
+// 按数值比较，长度不受 int64 限制；非数字段按折叠后的码点比较，数字与非数字相遇时数字按对应的 ASCII 数字参与比较。
+// 所有段都相同时依次以第一处次要差异（前导零个数少者在前，如 "file1" < "file01"；
+// 折叠前的码点，如 "File" < "file"）和原始字节序决胜，保证只有完全相同的字符串才比较为相等
+type NaturalCollator struct {
+	opts NaturalOptions
+}
+
+// NewNaturalCollator 创建自然排序比较器
+func NewNaturalCollator(opts NaturalOptions) *NaturalCollator {
+	return &NaturalCollator{opts: opts}
+}
+
+// defaultNaturalCollator 忽略大小写和全半角差异
+var defaultNaturalCollator = NewNaturalCollator(NaturalOptions{FoldCase: true, FoldWidth: true})
+
+// NaturalCompare 使用默认选项（忽略大小写和全半角）的自然排序比较，可直接用于 slices.SortFunc
+func NaturalCompare(a, b string) int {
+	return defaultNaturalCollator.Compare(a, b)
+}
+
+// Compare 三路比较 a 和 b
+func (c *NaturalCollator) Compare(a, b string) int {
+	origA, origB := a, b
+	tie := 0
+	for a != "" && b != "" {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+
+		if digitValue(ra) >= 0 && digitValue(rb) >= 0 {
+			var runA, runB string
+			runA, a = digitRun(a)
+			runB, b = digitRun(b)
+			if r := compareDigitRuns(runA, runB, &tie); r != 0 {
+				return r
+			}
+			continue
+		}
+
+		if fa, fb := c.rank(ra), c.rank(rb); fa != fb {
+			return cmp.Compare(fa, fb)
+		}
+		if tie == 0 {
+			tie = cmp.Compare(ra, rb)
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+
+	switch {
+	case a != "":
+		return 1
+	case b != "":
+		return -1
+	case tie != 0:
+		return tie
+	default:
+		return strings.Compare(origA, origB)
+	}
+}
+
+// Less sort.Slice 风格的比较
+func (c *NaturalCollator) Less(a, b string) bool {
+	return c.Compare(a, b) < 0
+}
+
+// rank 数字与非数字相遇时的比较依据：任何书写系统的数字都按对应的 ASCII 数字排列，
+// 其他字符按折叠后的码点排列，保证 "x١" < "x2" < "x:" 这样的比较满足传递性
+func (c *NaturalCollator) rank(r rune) rune {
+	if d := digitValue(r); d >= 0 {
+		return '0' + rune(d)
+	}
+	return c.fold(r)
+}
+
+// fold 按选项折叠字符
+func (c *NaturalCollator) fold(r rune) rune {
+	if c.opts.FoldWidth {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E: // 全角 ASCII
+			r -= 0xFEE0
+		case r == 0x3000: // 全角空格
+			r = ' '
+		}
+	}
+	if c.opts.FoldCase {
+		// 取大小写折叠等价类中最小的码点再转小写，使 "K"、"k" 和开尔文符号 "K" 折叠为同一字符
+		canonical := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			canonical = min(canonical, f)
+		}
+		r = unicode.ToLower(canonical)
+	}
+	return r
+}
+
+// digitValue 返回十进制数字字符（Unicode Nd 类，包括全角和其他书写系统的数字）的数值，非数字返回 -1。
+// Unicode 保证每组十进制数字 0-9 的码点连续，因此数值等于与所在连续数字区间起点的距离对 10 取模
+func digitValue(r rune) int {
+	if r >= '0' && r <= '9' {
+		return int(r - '0')
+	}
+	if r < utf8.RuneSelf || !unicode.IsDigit(r) {
+		return -1
+	}
+	zero := r
+	for unicode.IsDigit(zero - 1) {
+		zero--
+	}
+	return int(r-zero) % 10
+}
+
+// digitRun 返回 s 开头的连续数字及剩余部分
+func digitRun(s string) (run, rest string) {
+	end := 0
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if digitValue(r) < 0 {
+			break
+		}
+		end += size
+	}
+	return s[:end], s[end:]
+}
+
+// trimLeadingZeros 去掉数字段的前导零（保留至少一位），返回剩余部分和去掉的零的个数
+func trimLeadingZeros(run string) (string, int) {
+	zeros := 0
+	for {
+		r, size := utf8.DecodeRuneInString(run)
+		if digitValue(r) != 0 || size == len(run) {
+			return run, zeros
+		}
+		run = run[size:]
+		zeros++
+	}
+}
+
+// compareDigitRuns 按数值比较两个数字段：先比有效位数，再逐位比较；数值相等时把前导零或书写系统的差异记入 tie
+func compareDigitRuns(a, b string, tie *int) int {
+	a, zerosA := trimLeadingZeros(a)
+	b, zerosB := trimLeadingZeros(b)
+	if r := cmp.Compare(utf8.RuneCountInString(a), utf8.RuneCountInString(b)); r != 0 {
+		return r
+	}
+
+	scriptTie := 0
+	for a != "" {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		if r := cmp.Compare(digitValue(ra), digitValue(rb)); r != 0 {
+			return r
+		}
+		if scriptTie == 0 {
+			scriptTie = cmp.Compare(ra, rb)
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	if *tie == 0 {
+		*tie = cmp.Or(cmp.Compare(zerosA, zerosB), scriptTie)
+	}
+	return 0
+}
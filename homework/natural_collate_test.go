@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want int
+	}{
+		{"数字按数值比较", "file2", "file10", -1},
+		{"超过 uint64 的长数字", "v123456789012345678901234567890", "v99999999999999999999999999999", 1},
+		{"超过 uint64 的长数字逐位比较", "v123456789012345678901234567891", "v123456789012345678901234567890", 1},
+		{"前导零少者在前", "file1", "file01", -1},
+		{"前导零只在数值相等时决胜", "file01", "file2", -1},
+		{"全角数字按数值比较", "第１０章", "第9章", 1},
+		{"阿拉伯-印度数字按数值比较", "x١٢", "x3", 1},
+		{"不同书写系统的相同数值", "x12", "x١٢", -1},
+		{"忽略大小写", "alpha", "Beta", -1},
+		{"大小写差异推迟到最后", "File2", "file1", 1},
+		{"大小写决胜 大写在前", "File", "file", -1},
+		{"全半角折叠", "ｆｉｌｅ2", "file10", -1},
+		{"数字与非数字 阿拉伯-印度数字", "x١", "x:", -1},
+		{"数字与非数字 ASCII 数字", "x2", "x:", -1},
+		{"数字排在字母前", "x9", "xa", -1},
+		{"前缀在前", "file", "file1", -1},
+		{"完全相同", "a1b", "a1b", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sign(NaturalCompare(tt.a, tt.b)); got != tt.want {
+				t.Errorf("NaturalCompare(%q, %q) = %d, 期望 %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := sign(NaturalCompare(tt.b, tt.a)); got != -tt.want {
+				t.Errorf("NaturalCompare(%q, %q) = %d, 期望 %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
+
+func TestNaturalCollatorOptions(t *testing.T) {
+	exact := NewNaturalCollator(NaturalOptions{})
+	if got := sign(exact.Compare("B", "a")); got != -1 {
+		t.Errorf("不折叠大小写时 Compare(B, a) = %d, 期望 -1", got)
+	}
+	if got := sign(exact.Compare("ｆ", "g")); got != 1 {
+		t.Errorf("不折叠全半角时 Compare(ｆ, g) = %d, 期望 1", got)
+	}
+}
+
+// 混合语料的任意三元组都满足传递性，排序结果与输入顺序无关
+func TestNaturalCompareTransitive(t *testing.T) {
+	corpus := []string{
+		"x", "x1", "x01", "x١", "x2", "x٢", "x２", "x10", "x:", "x/", "x ", "xa", "xA", "x_",
+		"X1", "x1a", "x1A", "x1:", "x１０", "x99999999999999999999999", "x١٠٠", "x0", "x00",
+		"file", "File", "ｆｉｌｅ", "file1", "file01", "file001", "file1.txt", "file1-2", "file10",
+		"", "1", "١", "a", "ä", "Z",
+	}
+	for _, a := range corpus {
+		for _, b := range corpus {
+			ab := sign(NaturalCompare(a, b))
+			if ab != -sign(NaturalCompare(b, a)) {
+				t.Errorf("NaturalCompare(%q, %q) 与交换参数的结果不对称", a, b)
+			}
+			if (ab == 0) != (a == b) {
+				t.Errorf("NaturalCompare(%q, %q) = 0 只应出现在相同字符串之间", a, b)
+			}
+			for _, c := range corpus {
+				if ab < 0 && NaturalCompare(b, c) < 0 && NaturalCompare(a, c) >= 0 {
+					t.Errorf("不满足传递性: %q < %q < %q 但 %q >= %q", a, b, c, a, c)
+				}
+			}
+		}
+	}
+
+	sorted := slices.Clone(corpus)
+	slices.SortFunc(sorted, NaturalCompare)
+	reversed := slices.Clone(corpus)
+	slices.Reverse(reversed)
+	slices.SortFunc(reversed, NaturalCompare)
+	if !slices.Equal(sorted, reversed) {
+		t.Errorf("排序结果依赖输入顺序:\n%q\n%q", sorted, reversed)
+	}
+}
@@ -0,0 +1,295 @@
+// 订单优先级规则引擎：从 JSON 加载状态等级、加权评分和决胜规则，并解释两个订单的先后原因
+package main
+
+import (
+	"bytes"
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed order_rules.json
+var defaultOrderRulesJSON []byte
+
+// orderRulesPath 演示使用的订单规则文件，由 -order-rules 参数指定，为空时使用内置默认规则
+var orderRulesPath string
+
+// ErrInvalidOrderRules 订单规则配置无效，具体原因见包装它的错误信息
+var ErrInvalidOrderRules = errors.New("无效的订单规则")
+
+// OrderRules 订单排序规则的配置，依次按状态等级、综合得分和决胜规则比较
+type OrderRules struct {
+	// StatusRank 状态到等级的映射（状态名不区分大小写），等级小的排在前面
+	StatusRank map[string]int `json:"statusRank"`
+	// DefaultStatusRank 未列出的状态使用的等级
+	DefaultStatusRank int `json:"defaultStatusRank"`
+	// Scoring 加权评分项，状态等级相同时综合得分高的排在前面
+	Scoring []ScoringTerm `json:"scoring"`
+	// TieBreakers 得分也相同时的决胜规则，语法与 CompileSortSpec 相同，如 "priority asc, amount desc"
+	TieBreakers string `json:"tieBreakers"`
+}
+
+// ScoringTerm 一个评分项：得分为 字段值 × 权重，设置 Cap 时得分的绝对值不超过 Cap
+type ScoringTerm struct {
+	// Field 评分字段: amount（金额）、priority（优先级数字）或 ageHours（下单至今的小时数）
+	Field  string  `json:"field"`
+	Weight float64 `json:"weight"`
+	Cap    float64 `json:"cap,omitempty"`
+}
+
+// orderScoreFields 评分项可以使用的字段
+var orderScoreFields = map[string]func(o BestPracticeOrder, now time.Time) float64{
+	"amount":   func(o BestPracticeOrder, _ time.Time) float64 { return o.Amount },
+	"priority": func(o BestPracticeOrder, _ time.Time) float64 { return float64(o.Priority) },
+	"agehours": func(o BestPracticeOrder, now time.Time) float64 {
+		if o.Date.IsZero() {
+			return 0
+		}
+		return now.Sub(o.Date).Hours()
+	},
+}
+
+// OrderRuleEngine 编译后的订单排序规则，可作为 Comparator 使用
+type OrderRuleEngine struct {
+	rules      OrderRules
+	statusRank map[string]int
+	scoring    []compiledScoringTerm
+	ties       []compiledTieBreaker
+	// now 计算 ageHours 的参考时刻；固定下来保证同一次排序中的得分一致
+	now time.Time
+}
+
+type compiledScoringTerm struct {
+	ScoringTerm
+	value func(o BestPracticeOrder, now time.Time) float64
+}
+
+type compiledTieBreaker struct {
+	term  sortTerm
+	field fieldComparator
+}
+
+// NewOrderRuleEngine 校验并编译规则，ageHours 以当前时刻为参考
+func NewOrderRuleEngine(rules OrderRules) (*OrderRuleEngine, error) {
+	e := &OrderRuleEngine{rules: rules, statusRank: make(map[string]int), now: time.Now()}
+	// 按状态名顺序遍历，只有大小写不同的重复状态每次都报告同一对名字
+	seen := make(map[string]string)
+	for _, status := range slices.Sorted(maps.Keys(rules.StatusRank)) {
+		key := strings.ToLower(strings.TrimSpace(status))
+		if key == "" {
+			return nil, fmt.Errorf("%w: statusRank 中有空的状态名", ErrInvalidOrderRules)
+		}
+		if prev, dup := seen[key]; dup {
+			return nil, fmt.Errorf("%w: statusRank 中的状态 %q 与 %q 重复（状态名不区分大小写）",
+				ErrInvalidOrderRules, prev, status)
+		}
+		seen[key] = status
+		e.statusRank[key] = rules.StatusRank[status]
+	}
+	for i, term := range rules.Scoring {
+		value, ok := orderScoreFields[strings.ToLower(term.Field)]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: 第 %d 个评分项的字段 %q 无效，应为 amount、priority 或 ageHours",
+				ErrInvalidOrderRules, i+1, term.Field)
+		case math.IsNaN(term.Weight) || math.IsInf(term.Weight, 0):
+			return nil, fmt.Errorf("%w: 第 %d 个评分项 %q 的权重无效", ErrInvalidOrderRules, i+1, term.Field)
+		case term.Cap < 0:
+			return nil, fmt.Errorf("%w: 第 %d 个评分项 %q 的 cap 不能为负数", ErrInvalidOrderRules, i+1, term.Field)
+		}
+		e.scoring = append(e.scoring, compiledScoringTerm{term, value})
+	}
+	if strings.TrimSpace(rules.TieBreakers) != "" {
+		terms, err := parseSortSpec(rules.TieBreakers)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tieBreakers: %w", ErrInvalidOrderRules, err)
+		}
+		for _, term := range terms {
+			fc, err := compileSortTerm(reflect.TypeFor[BestPracticeOrder](), term)
+			if err != nil {
+				return nil, fmt.Errorf("%w: tieBreakers: %w", ErrInvalidOrderRules, err)
+			}
+			e.ties = append(e.ties, compiledTieBreaker{term, fc})
+		}
+	}
+	return e, nil
+}
+
+// LoadOrderRules 从 JSON 读取并编译规则，未知的配置项视为错误以便发现拼写问题
+func LoadOrderRules(r io.Reader) (*OrderRuleEngine, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var rules OrderRules
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: 解析 JSON 失败: %w", ErrInvalidOrderRules, err)
+	}
+	return NewOrderRuleEngine(rules)
+}
+
+// LoadOrderRulesFile 从文件加载规则，运营人员修改文件后重新加载即可生效，无需改代码
+func LoadOrderRulesFile(path string) (*OrderRuleEngine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开订单规则文件失败: %w", err)
+	}
+	defer f.Close()
+	return LoadOrderRules(f)
+}
+
+// DefaultOrderRuleEngine 内置的默认规则：urgent 优先，然后按优先级数字、金额降序、下单时间
+func DefaultOrderRuleEngine() *OrderRuleEngine {
+	e, err := LoadOrderRules(bytes.NewReader(defaultOrderRulesJSON))
+	if err != nil {
+		panic(fmt.Sprintf("内置订单规则无效: %v", err))
+	}
+	return e
+}
+
+// At 返回以 t 为 ageHours 参考时刻的引擎副本
+func (e *OrderRuleEngine) At(t time.Time) *OrderRuleEngine {
+	c := *e
+	c.now = t
+	return &c
+}
+
+// Rules 返回引擎使用的规则
+func (e *OrderRuleEngine) Rules() OrderRules {
+	return e.rules
+}
+
+func (e *OrderRuleEngine) rank(status string) int {
+	if rank, ok := e.statusRank[strings.ToLower(status)]; ok {
+		return rank
+	}
+	return e.rules.DefaultStatusRank
+}
+
+// Score 返回订单的综合得分
+func (e *OrderRuleEngine) Score(o BestPracticeOrder) float64 {
+	total := 0.0
+	for _, term := range e.scoring {
+		total += term.score(o, e.now)
+	}
+	return total
+}
+
+func (t compiledScoringTerm) score(o BestPracticeOrder, now time.Time) float64 {
+	s := t.value(o, now) * t.Weight
+	if t.Cap > 0 {
+		s = max(-t.Cap, min(t.Cap, s))
+	}
+	return s
+}
+
+// Compare 三路比较，排在前面的订单更小，可直接传给 slices.SortFunc
+func (e *OrderRuleEngine) Compare(a, b BestPracticeOrder) int {
+	if r := cmp.Compare(e.rank(a.Status), e.rank(b.Status)); r != 0 {
+		return r
+	}
+	if len(e.scoring) > 0 {
+		if r := cmp.Compare(e.Score(b), e.Score(a)); r != 0 {
+			return r
+		}
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for _, tie := range e.ties {
+		if r := tie.field.compare(va, vb); r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+// Comparator 以比较器形式返回规则，便于与 Then、TopK 等组合
+func (e *OrderRuleEngine) Comparator() Comparator[BestPracticeOrder] {
+	return e.Compare
+}
+
+// Explain 用一句话说明 a 与 b 的先后关系由哪条规则决定
+func (e *OrderRuleEngine) Explain(a, b BestPracticeOrder) string {
+	first, second := a, b
+	if e.Compare(a, b) > 0 {
+		first, second = b, a
+	}
+
+	rankA, rankB := e.rank(first.Status), e.rank(second.Status)
+	if rankA != rankB {
+		return fmt.Sprintf("%s 排在 %s 之前: 状态 %s 的等级 %d 高于 %s 的等级 %d",
+			first.ID, second.ID, first.Status, rankA, second.Status, rankB)
+	}
+	if len(e.scoring) > 0 {
+		scoreA, scoreB := e.Score(first), e.Score(second)
+		if scoreA != scoreB {
+			return fmt.Sprintf("%s 排在 %s 之前: 状态等级相同 (%d)，综合得分 %.2f [%s] 高于 %.2f [%s]",
+				first.ID, second.ID, rankA, scoreA, e.scoreBreakdown(first), scoreB, e.scoreBreakdown(second))
+		}
+	}
+	same := "状态等级相同"
+	if len(e.scoring) > 0 {
+		same = "状态等级和得分相同"
+	}
+	va, vb := reflect.ValueOf(first), reflect.ValueOf(second)
+	for _, tie := range e.ties {
+		if tie.field.compare(va, vb) != 0 {
+			dir := "升序"
+			if tie.term.desc {
+				dir = "降序"
+			}
+			return fmt.Sprintf("%s 排在 %s 之前: %s，按 %s %s决胜: %s vs %s",
+				first.ID, second.ID, same, strings.Join(tie.term.path, "."), dir,
+				formatRuleValue(tie.field, va), formatRuleValue(tie.field, vb))
+		}
+	}
+	return fmt.Sprintf("%s 与 %s 按所有规则都相同，稳定排序时保持原有顺序", a.ID, b.ID)
+}
+
+// scoreBreakdown 列出各评分项的贡献，如 "priority -100×1=-100, amount 0.05×2000=100(封顶)"
+func (e *OrderRuleEngine) scoreBreakdown(o BestPracticeOrder) string {
+	parts := make([]string, len(e.scoring))
+	for i, term := range e.scoring {
+		value := term.value(o, e.now)
+		score := term.score(o, e.now)
+		parts[i] = fmt.Sprintf("%s %g×%.4g=%.2f", term.Field, term.Weight, value, score)
+		if score != value*term.Weight {
+			parts[i] += "(封顶)"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatRuleValue(fc fieldComparator, root reflect.Value) string {
+	v, ok := fc.resolve(root)
+	if !ok {
+		return "<空>"
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return "<未填写>"
+		}
+		return x.Format("2006-01-02 15:04")
+	case float64:
+		return fmt.Sprintf("%.2f", x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// explainOrderRanking 打印排序结果中每对相邻订单的先后原因
+func explainOrderRanking(e *OrderRuleEngine, orders []BestPracticeOrder) {
+	fmt.Println("排序原因:")
+	for i := 1; i < len(orders); i++ {
+		fmt.Printf("  %s\n", e.Explain(orders[i-1], orders[i]))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 内置默认规则与原先写死的比较器链给出相同的顺序
+func TestDefaultOrderRulesMatchHardCodedOrder(t *testing.T) {
+	legacy := OrderUrgentFirst.Then(OrderByPriority).Then(OrderByAmount.Reverse()).Then(OrderByDate)
+	engine := DefaultOrderRuleEngine()
+
+	// randomOrders 的下单时间都不为零，且金额、优先级大量重复，能覆盖每一级决胜
+	orders := randomOrders(300)
+	orders = append(orders, orders[0])
+	for _, a := range orders {
+		for _, b := range orders {
+			if got, want := sign(engine.Compare(a, b)), sign(legacy(a, b)); got != want {
+				t.Fatalf("Compare(%+v, %+v) = %d, 原比较器为 %d", a, b, got, want)
+			}
+		}
+	}
+
+	got, want := slices.Clone(orders), slices.Clone(orders)
+	slices.SortStableFunc(got, engine.Compare)
+	slices.SortStableFunc(want, legacy)
+	if !slices.Equal(orderIDs(got), orderIDs(want)) {
+		t.Error("默认规则的排序结果与原比较器不一致")
+	}
+
+	// 唯一的差别：规则中的状态名不区分大小写
+	upper := BestPracticeOrder{ID: "upper", Status: "URGENT", Priority: 3}
+	pending := BestPracticeOrder{ID: "pending", Status: "pending", Priority: 1}
+	if engine.Compare(upper, pending) >= 0 {
+		t.Error("URGENT 应按 urgent 的等级排在前面")
+	}
+}
+
+func TestOrderRuleScoring(t *testing.T) {
+	engine, err := NewOrderRuleEngine(OrderRules{
+		Scoring: []ScoringTerm{
+			{Field: "priority", Weight: -100},
+			{Field: "Amount", Weight: 0.05, Cap: 100},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		order BestPracticeOrder
+		want  float64
+	}{
+		{"未达封顶", BestPracticeOrder{Amount: 1000, Priority: 1}, -100 + 50},
+		{"正好封顶", BestPracticeOrder{Amount: 2000, Priority: 2}, -200 + 100},
+		{"超过封顶", BestPracticeOrder{Amount: 9000, Priority: 1}, -100 + 100},
+		{"负数得分按绝对值封顶", BestPracticeOrder{Amount: -5000, Priority: 3}, -300 - 100},
+	}
+	for _, tt := range tests {
+		if got := engine.Score(tt.order); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Score = %v, 期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 得分高的排在前面：封顶后金额再大也追不上优先级数字小一级的订单
+	big := BestPracticeOrder{ID: "big", Amount: 1e6, Priority: 2}
+	small := BestPracticeOrder{ID: "small", Amount: 10, Priority: 1}
+	if engine.Compare(small, big) >= 0 {
+		t.Errorf("期望 small 排在 big 之前, 得分 %v vs %v", engine.Score(small), engine.Score(big))
+	}
+}
+
+func TestOrderRuleAt(t *testing.T) {
+	engine, err := NewOrderRuleEngine(OrderRules{Scoring: []ScoringTerm{{Field: "ageHours", Weight: 1, Cap: 72}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2023, 12, 3, 12, 0, 0, 0, time.UTC)
+	order := BestPracticeOrder{Date: now.Add(-5 * time.Hour)}
+
+	at := engine.At(now)
+	if got := at.Score(order); got != 5 {
+		t.Errorf("At(now).Score = %v, 期望 5", got)
+	}
+	if got := at.Score(order); got != 5 {
+		t.Errorf("同一引擎再次计算得分 = %v, 期望仍为 5", got)
+	}
+	if got := engine.At(now.Add(24 * time.Hour)).Score(order); got != 29 {
+		t.Errorf("一天后的得分 = %v, 期望 29", got)
+	}
+	if got := engine.At(now.Add(30 * 24 * time.Hour)).Score(order); got != 72 {
+		t.Errorf("ageHours 封顶后的得分 = %v, 期望 72", got)
+	}
+	if got := at.Score(BestPracticeOrder{}); got != 0 {
+		t.Errorf("未填写下单时间的得分 = %v, 期望 0", got)
+	}
+	if at.now == engine.now || !at.now.Equal(now) {
+		t.Error("At 应返回副本而不修改原引擎的参考时刻")
+	}
+}
+
+func TestLoadOrderRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"未知配置项", `{"statusRank":{"urgent":0},"tieBreaker":"priority"}`},
+		{"评分项中的未知配置项", `{"scoring":[{"field":"amount","weight":1,"max":10}]}`},
+		{"无效的评分字段", `{"scoring":[{"field":"discount","weight":1}]}`},
+		{"负数封顶", `{"scoring":[{"field":"amount","weight":1,"cap":-1}]}`},
+		{"决胜规则语法错误", `{"tieBreakers":"priority up"}`},
+		{"决胜规则中的未知字段", `{"tieBreakers":"priority asc, missing desc"}`},
+		{"空的状态名", `{"statusRank":{" ":0}}`},
+		{"只有大小写不同的状态", `{"statusRank":{"Urgent":0,"urgent":1}}`},
+		{"JSON 格式错误", `{"statusRank":`},
+	}
+	for _, tt := range tests {
+		if _, err := LoadOrderRules(strings.NewReader(tt.json)); !errors.Is(err, ErrInvalidOrderRules) {
+			t.Errorf("%s: 错误 = %v, 期望 ErrInvalidOrderRules", tt.name, err)
+		}
+	}
+
+	// JSON 无法表示 NaN 和 Inf，直接构造规则检查
+	for _, w := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		rules := OrderRules{Scoring: []ScoringTerm{{Field: "amount", Weight: w}}}
+		if _, err := NewOrderRuleEngine(rules); !errors.Is(err, ErrInvalidOrderRules) {
+			t.Errorf("权重 %v: 错误 = %v, 期望 ErrInvalidOrderRules", w, err)
+		}
+	}
+
+	// 重复状态的错误信息不随 map 遍历顺序变化
+	rules := OrderRules{StatusRank: map[string]int{"urgent": 0, "Urgent": 1, "URGENT": 2}}
+	_, first := NewOrderRuleEngine(rules)
+	for range 20 {
+		if _, err := NewOrderRuleEngine(rules); err == nil || err.Error() != first.Error() {
+			t.Fatalf("重复状态的错误信息不稳定: %v vs %v", err, first)
+		}
+	}
+}
+
+func TestOrderRuleExplain(t *testing.T) {
+	engine, err := LoadOrderRules(strings.NewReader(`{
+		"statusRank": {"urgent": 0},
+		"defaultStatusRank": 1,
+		"scoring": [{"field": "amount", "weight": 1}],
+		"tieBreakers": "priority asc"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := func(id, status string, amount float64, priority int) BestPracticeOrder {
+		return BestPracticeOrder{ID: id, Status: status, Amount: amount, Priority: priority}
+	}
+	tests := []struct {
+		name string
+		a, b BestPracticeOrder
+		want []string
+	}{
+		{"状态决定", order("A", "pending", 900, 1), order("B", "urgent", 10, 3),
+			[]string{"B 排在 A 之前", "状态 urgent 的等级 0", "pending 的等级 1"}},
+		{"得分决定", order("A", "pending", 100, 1), order("B", "processing", 200, 3),
+			[]string{"B 排在 A 之前", "状态等级相同 (1)", "综合得分 200.00", "amount 1×200=200.00"}},
+		{"决胜规则决定", order("A", "urgent", 100, 2), order("B", "urgent", 100, 1),
+			[]string{"B 排在 A 之前", "状态等级和得分相同", "按 priority 升序决胜: 1 vs 2"}},
+		{"完全相同", order("A", "urgent", 100, 1), order("B", "URGENT", 100, 1),
+			[]string{"按所有规则都相同", "稳定排序时保持原有顺序"}},
+	}
+	for _, tt := range tests {
+		// 参数顺序不影响解释
+		for _, got := range []string{engine.Explain(tt.a, tt.b), engine.Explain(tt.b, tt.a)} {
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("%s: Explain = %q, 期望包含 %q", tt.name, got, want)
+				}
+			}
+		}
+	}
+}
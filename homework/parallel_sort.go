@@ -0,0 +1,181 @@
+// 分块并行排序与 k 路堆归并
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ParallelSortThreshold 元素数低于该值时 ParallelSort 直接顺序排序，
+// 此时启动协程和归并的开销超过并行带来的收益（取值参考 BenchmarkParallelSort 的交叉点）
+var ParallelSortThreshold = 1 << 15
+
+// ParallelSort 将 s 切分为 GOMAXPROCS 个分块并行排序，再用 k 路堆归并合成有序结果。
+// 不保证相等元素的相对顺序，需要时使用 ParallelSortStable
+func ParallelSort[T any](s []T, cmp func(a, b T) int) {
+	parallelSort(s, cmp, false, ParallelSortThreshold, runtime.GOMAXPROCS(0))
+}
+
+// ParallelSortStable 稳定的并行排序：分块内使用稳定排序，归并时相等元素优先取靠前的分块
+func ParallelSortStable[T any](s []T, cmp func(a, b T) int) {
+	parallelSort(s, cmp, true, ParallelSortThreshold, runtime.GOMAXPROCS(0))
+}
+
+func parallelSort[T any](s []T, cmp func(a, b T) int, stable bool, threshold, workers int) {
+	sortChunk := slices.SortFunc[[]T]
+	if stable {
+		sortChunk = slices.SortStableFunc[[]T]
+	}
+	// 分块太小时同样退化为顺序排序
+	workers = min(workers, len(s)/64)
+	if len(s) < threshold || workers < 2 {
+		sortChunk(s, cmp)
+		return
+	}
+
+	chunks := make([][]T, workers)
+	size := (len(s) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for i := range chunks {
+		chunk := s[min(i*size, len(s)):min((i+1)*size, len(s))]
+		chunks[i] = chunk
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sortChunk(chunk, cmp)
+		}()
+	}
+	wg.Wait()
+
+	merged := make([]T, 0, len(s))
+	h := newMergeHeap(cmp)
+	for i, chunk := range chunks {
+		if len(chunk) > 0 {
+			h.push(chunk[0], i)
+		}
+	}
+	next := make([]int, len(chunks))
+	for h.len() > 0 {
+		v, src := h.top()
+		merged = append(merged, v)
+		next[src]++
+		if next[src] < len(chunks[src]) {
+			h.replaceTop(chunks[src][next[src]], src)
+		} else {
+			h.pop()
+		}
+	}
+	copy(s, merged)
+}
+
+// mergeHeap k 路归并使用的最小堆，元素记录其来源（分块或归并段的序号）。
+// 比较结果相等时来源序号小的在前，保证归并是稳定的；TopKSeq 也用它保留前 k 个元素
+type mergeHeap[T any] struct {
+	cmp   func(a, b T) int
+	items []mergeItem[T]
+}
+
+type mergeItem[T any] struct {
+	value  T
+	source int
+}
+
+func newMergeHeap[T any](cmp func(a, b T) int) *mergeHeap[T] {
+	return &mergeHeap[T]{cmp: cmp}
+}
+
+func (h *mergeHeap[T]) len() int { return len(h.items) }
+
+func (h *mergeHeap[T]) less(i, j int) bool {
+	if c := h.cmp(h.items[i].value, h.items[j].value); c != 0 {
+		return c < 0
+	}
+	return h.items[i].source < h.items[j].source
+}
+
+func (h *mergeHeap[T]) push(v T, source int) {
+	h.items = append(h.items, mergeItem[T]{v, source})
+	for i := len(h.items) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+// top 返回堆顶元素及其来源
+func (h *mergeHeap[T]) top() (T, int) {
+	return h.items[0].value, h.items[0].source
+}
+
+// replaceTop 用来源相同的下一个元素替换堆顶，比 pop+push 少一次调整
+func (h *mergeHeap[T]) replaceTop(v T, source int) {
+	h.items[0] = mergeItem[T]{v, source}
+	h.down(0)
+}
+
+func (h *mergeHeap[T]) pop() (T, int) {
+	item := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items[last] = mergeItem[T]{}
+	h.items = h.items[:last]
+	if last > 0 {
+		h.down(0)
+	}
+	return item.value, item.source
+}
+
+func (h *mergeHeap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && h.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && h.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
+
+// demonstrateParallelSort 对比顺序排序与并行排序处理 100 万个订单金额的耗时
+func demonstrateParallelSort() {
+	fmt.Println("  并行排序示例 (100万个订单, 按金额降序 -> 订单号):")
+	orders := make([]BestPracticeOrder, 1_000_000)
+	for i := range orders {
+		orders[i] = BestPracticeOrder{
+			ID:     fmt.Sprintf("ORD%07d", i),
+			Amount: float64(rand.IntN(100_000)) / 10,
+		}
+	}
+	byAmount := OrderByAmount.Reverse().Then(OrderByID)
+
+	sequential := slices.Clone(orders)
+	start := time.Now()
+	slices.SortFunc(sequential, byAmount)
+	sequentialTime := time.Since(start)
+
+	parallel := slices.Clone(orders)
+	start = time.Now()
+	ParallelSort(parallel, byAmount)
+	parallelTime := time.Since(start)
+
+	fmt.Printf("     slices.SortFunc: %v, ParallelSort: %v (GOMAXPROCS=%d), 结果一致: %v\n",
+		sequentialTime.Round(time.Millisecond), parallelTime.Round(time.Millisecond),
+		runtime.GOMAXPROCS(0), slices.EqualFunc(sequential, parallel, func(a, b BestPracticeOrder) bool {
+			return a.ID == b.ID
+		}))
+	fmt.Println()
+}
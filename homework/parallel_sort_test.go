@@ -0,0 +1,72 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestParallelSortStableMatchesSortStableFunc(t *testing.T) {
+	// 金额只有 50 种取值，大量相等元素分布在不同分块中，检验归并时相等元素优先取靠前的分块
+	orders := randomOrders(1 << 16)
+	for i := range orders {
+		orders[i].Amount = float64(rand.IntN(50) * 100)
+	}
+	want := slices.Clone(orders)
+	slices.SortStableFunc(want, OrderByAmount)
+
+	for _, workers := range []int{2, 3, 8} {
+		got := slices.Clone(orders)
+		parallelSort(got, OrderByAmount, true, 0, workers)
+		if !slices.Equal(orderIDs(got), orderIDs(want)) {
+			t.Errorf("workers=%d: ParallelSortStable 与 slices.SortStableFunc 结果不一致", workers)
+		}
+	}
+	got := slices.Clone(orders)
+	ParallelSortStable(got, OrderByAmount)
+	if !slices.Equal(orderIDs(got), orderIDs(want)) {
+		t.Error("ParallelSortStable 与 slices.SortStableFunc 结果不一致")
+	}
+}
+
+func TestParallelSortSortedPermutation(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for _, n := range []int{0, 1, 100, ParallelSortThreshold - 1, ParallelSortThreshold, 3*ParallelSortThreshold + 7} {
+		data := make([]int, n)
+		for i := range data {
+			data[i] = rand.IntN(n/4 + 1)
+		}
+		got := slices.Clone(data)
+		ParallelSort(got, cmp.Compare[int])
+		if !slices.IsSorted(got) {
+			t.Errorf("n=%d: ParallelSort 结果未排序", n)
+		}
+		if !isPermutation(got, data) {
+			t.Errorf("n=%d: ParallelSort 结果不是输入的排列", n)
+		}
+	}
+}
+
+// BenchmarkParallelSort 在不同数据规模下对比使用同一比较函数的 slices.SortFunc 与强制并行的 ParallelSort，
+// 找出并行开始占优的交叉点（单核环境下并行只有额外开销，不会出现交叉点）
+func BenchmarkParallelSort(b *testing.B) {
+	compareInts := cmp.Compare[int]
+	for _, n := range []int{1 << 10, 1 << 12, 1 << 14, 1 << 16, 1 << 18, 1 << 20} {
+		data := make([]int, n)
+		for i := range data {
+			data[i] = rand.Int()
+		}
+		b.Run(fmt.Sprintf("n=%d/SortFunc", n), func(b *testing.B) {
+			benchmarkOnCopy(b, data, func(s []int) { slices.SortFunc(s, compareInts) })
+		})
+		b.Run(fmt.Sprintf("n=%d/ParallelSort", n), func(b *testing.B) {
+			benchmarkOnCopy(b, data, func(s []int) { parallelSort(s, compareInts, false, 0, runtime.GOMAXPROCS(0)) })
+		})
+		b.Run(fmt.Sprintf("n=%d/ParallelSortStable", n), func(b *testing.B) {
+			benchmarkOnCopy(b, data, func(s []int) { parallelSort(s, compareInts, true, 0, runtime.GOMAXPROCS(0)) })
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// 基础类型的非比较排序：整数/浮点数 LSD 基数排序、字符串 MSD 基数排序和小范围计数排序
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Integer 所有整数类型
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Float 所有浮点数类型
+type Float interface {
+	~float32 | ~float64
+}
+
+// integerKey 将整数映射为保序的无符号键：有符号数翻转符号位，使负数排在正数之前
+func integerKey[T Integer](v T) uint64 {
+	if ^T(0) < 0 {
+		return uint64(int64(v)) ^ (1 << 63)
+	}
+	return uint64(v)
+}
+
+// floatKey 将浮点数映射为保序的无符号键：正数置符号位，负数按位取反；
+// 所有 NaN 映射为最小的键，与 slices.Sort 一样排在最前。-0 排在 +0 之前
+func floatKey[T Float](v T) uint64 {
+	f := float64(v)
+	if math.IsNaN(f) {
+		return 0
+	}
+	bits := math.Float64bits(f)
+	if bits&(1<<63) != 0 {
+		return ^bits
+	}
+	return bits | 1<<63
+}
+
+// RadixSortInts 对整数做 LSD 基数排序（每轮 8 位，稳定），所有元素该字节相同的轮次会被跳过，
+// 因此较窄的类型或取值集中的数据只需要很少的轮数
+func RadixSortInts[T Integer](s []T) {
+	radixSortByKey(s, integerKey[T])
+}
+
+// RadixSortFloats 对浮点数做 LSD 基数排序，NaN 排在最前
+func RadixSortFloats[T Float](s []T) {
+	radixSortByKey(s, floatKey[T])
+}
+
+// radixSortByKey 按 64 位无符号键做 LSD 基数排序
+func radixSortByKey[T any](s []T, key func(T) uint64) {
+	if len(s) < 64 {
+		slices.SortStableFunc(s, func(a, b T) int {
+			ka, kb := key(a), key(b)
+			switch {
+			case ka < kb:
+				return -1
+			case ka > kb:
+				return 1
+			default:
+				return 0
+			}
+		})
+		return
+	}
+
+	// 一次遍历统计 8 个字节位置的分布
+	var counts [8][256]int
+	for _, v := range s {
+		k := key(v)
+		for pass := 0; pass < 8; pass++ {
+			counts[pass][byte(k>>(8*pass))]++
+		}
+	}
+
+	src, dst := s, make([]T, len(s))
+	for pass := 0; pass < 8; pass++ {
+		c := &counts[pass]
+		if c[byte(key(src[0])>>(8*pass))] == len(src) {
+			continue
+		}
+		offset := 0
+		for b := range c {
+			offset, c[b] = offset+c[b], offset
+		}
+		for _, v := range src {
+			b := byte(key(v) >> (8 * pass))
+			dst[c[b]] = v
+			c[b]++
+		}
+		src, dst = dst, src
+	}
+	if &src[0] != &s[0] {
+		copy(s, src)
+	}
+}
+
+// RadixSortStrings 按字节对字符串做 MSD 基数排序，结果与 slices.Sort 一致
+func RadixSortStrings(s []string) {
+	msdRadixSort(s, make([]string, len(s)), 0)
+}
+
+// msdRadixSort 按第 depth 个字节分桶后递归排序各桶；已结束的字符串排在最前，小桶改用比较排序
+func msdRadixSort(s, buf []string, depth int) {
+	for len(s) >= 32 {
+		// 桶 0 存放长度恰为 depth 的字符串，桶 b+1 存放第 depth 个字节为 b 的字符串
+		var counts [257]int
+		for _, str := range s {
+			counts[byteBucket(str, depth)]++
+		}
+		if counts[byteBucket(s[0], depth)] == len(s) {
+			if byteBucket(s[0], depth) == 0 {
+				return // 全部相同
+			}
+			depth++
+			continue
+		}
+
+		var starts [257]int
+		offset := 0
+		for b, n := range counts {
+			starts[b] = offset
+			offset += n
+		}
+		next := starts
+		for _, str := range s {
+			b := byteBucket(str, depth)
+			buf[next[b]] = str
+			next[b]++
+		}
+		copy(s, buf[:len(s)])
+
+		for b := 1; b < len(counts); b++ {
+			if counts[b] > 1 {
+				lo, hi := starts[b], starts[b]+counts[b]
+				msdRadixSort(s[lo:hi], buf[lo:hi], depth+1)
+			}
+		}
+		return
+	}
+	// 同一桶中的字符串前 depth 个字节相同，直接比较整个字符串即可
+	slices.Sort(s)
+}
+
+func byteBucket(s string, depth int) int {
+	if depth >= len(s) {
+		return 0
+	}
+	return int(s[depth]) + 1
+}
+
+// countingSortMaxRange 计数排序允许的最大取值范围，超过时改用基数排序
+const countingSortMaxRange = 1 << 16
+
+// CountingSort 对取值范围较小的整数（如年龄、评分、状态码）做计数排序，时间 O(n+k)；
+// 取值范围超过 countingSortMaxRange 或超过元素数的 4 倍时退化为 RadixSortInts
+func CountingSort[T Integer](s []T) {
+	if len(s) < 2 {
+		return
+	}
+	lo, hi := integerKey(s[0]), integerKey(s[0])
+	for _, v := range s[1:] {
+		k := integerKey(v)
+		lo, hi = min(lo, k), max(hi, k)
+	}
+	span := hi - lo
+	if span >= countingSortMaxRange || span > uint64(4*len(s)) {
+		RadixSortInts(s)
+		return
+	}
+
+	counts := make([]int, span+1)
+	values := make([]T, span+1)
+	for _, v := range s {
+		k := integerKey(v) - lo
+		counts[k]++
+		values[k] = v
+	}
+	i := 0
+	for k, n := range counts {
+		for ; n > 0; n-- {
+			s[i] = values[k]
+			i++
+		}
+	}
+}
+
+// demonstrateRadixSorts 展示基础类型专用排序对特殊值的处理
+func demonstrateRadixSorts() {
+	fmt.Println("  基础类型专用排序:")
+	floats := []float64{3.5, math.NaN(), -0.0, math.Inf(1), -2.25, 0, math.Inf(-1), 1e-300, -1e300}
+	RadixSortFloats(floats)
+	fmt.Printf("     RadixSortFloats: %v\n", floats)
+
+	ints := []int32{42, -7, math.MaxInt32, 0, math.MinInt32, -1, 7}
+	RadixSortInts(ints)
+	fmt.Printf("     RadixSortInts:   %v\n", ints)
+
+	words := []string{"banana", "", "apple", "app", "Banana", "apple", "中文", "b"}
+	RadixSortStrings(words)
+	fmt.Printf("     RadixSortStrings: %q\n", words)
+
+	ages := []uint8{35, 28, 42, 28, 61, 19, 35}
+	CountingSort(ages)
+	fmt.Printf("     CountingSort:    %v\n", ages)
+	fmt.Println()
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// 同时覆盖比较排序路径（少于 64 个元素）和基数排序路径
+var radixSortSizes = []int{0, 1, 10, 63, 64, 1000, 5000}
+
+func TestRadixSortInts(t *testing.T) {
+	for _, n := range radixSortSizes {
+		int64s := make([]int64, n)
+		int8s := make([]int8, n)
+		uint16s := make([]uint16, n)
+		for i := range int64s {
+			int64s[i] = rand.Int64() - math.MaxInt64/2
+			int8s[i] = int8(rand.IntN(256) - 128)
+			uint16s[i] = uint16(rand.IntN(1 << 16))
+		}
+		if n >= 4 {
+			int64s[0], int64s[1], int64s[2], int64s[3] = math.MaxInt64, math.MinInt64, -1, 0
+			int8s[0], int8s[1] = math.MaxInt8, math.MinInt8
+		}
+		checkRadixSort(t, fmt.Sprintf("int64/%d", n), int64s, RadixSortInts[int64])
+		checkRadixSort(t, fmt.Sprintf("int8/%d", n), int8s, RadixSortInts[int8])
+		checkRadixSort(t, fmt.Sprintf("uint16/%d", n), uint16s, RadixSortInts[uint16])
+	}
+}
+
+func TestRadixSortFloats(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	for _, n := range radixSortSizes {
+		s := make([]float64, n)
+		for i := range s {
+			switch i % 7 {
+			case 0:
+				s[i] = math.NaN()
+			case 1:
+				s[i] = negZero
+			case 2:
+				s[i] = 0
+			case 3:
+				s[i] = math.Inf(1 - 2*(i%2))
+			default:
+				s[i] = rand.NormFloat64() * 1e6
+			}
+		}
+		got := slices.Clone(s)
+		RadixSortFloats(got)
+		want := slices.Clone(s)
+		slices.Sort(want)
+		if !slices.EqualFunc(got, want, func(a, b float64) bool { return a == b || math.IsNaN(a) && math.IsNaN(b) }) {
+			t.Errorf("float64/%d: RadixSortFloats 与 slices.Sort 结果不一致", n)
+		}
+		// slices.Sort 把 -0 和 +0 视为相等，这里单独检查 -0 都排在 +0 之前，NaN 都排在最前
+		seenPosZero := false
+		for i, f := range got {
+			if math.IsNaN(f) && i > 0 && !math.IsNaN(got[i-1]) {
+				t.Errorf("float64/%d: 第 %d 个元素 NaN 排在非 NaN 之后", n, i)
+			}
+			if f == 0 {
+				if math.Signbit(f) && seenPosZero {
+					t.Errorf("float64/%d: -0 排在 +0 之后", n)
+				}
+				seenPosZero = seenPosZero || !math.Signbit(f)
+			}
+		}
+	}
+
+	f32 := []float32{float32(math.NaN()), 2.5, -1, float32(negZero), 0, float32(math.Inf(-1))}
+	RadixSortFloats(f32)
+	if !math.IsNaN(float64(f32[0])) || f32[1] != float32(math.Inf(-1)) || !math.Signbit(float64(f32[3])) || f32[5] != 2.5 {
+		t.Errorf("float32 排序结果 = %v", f32)
+	}
+}
+
+func TestRadixSortStrings(t *testing.T) {
+	prefixes := []string{"", "a", "ab", "abc", "user-", "user-00", "用户", "用户名", "é", "\xff", "\x00"}
+	for _, n := range radixSortSizes {
+		s := make([]string, n)
+		for i := range s {
+			// 共享前缀、空串、非 ASCII 字节和重复值
+			s[i] = prefixes[rand.IntN(len(prefixes))] + prefixes[rand.IntN(len(prefixes))]
+			if i%5 == 0 {
+				s[i] += fmt.Sprint(rand.IntN(100))
+			}
+		}
+		checkRadixSort(t, fmt.Sprintf("string/%d", n), s, RadixSortStrings)
+	}
+	same := slices.Repeat([]string{"重复的长字符串"}, 100)
+	checkRadixSort(t, "string/全部相同", same, RadixSortStrings)
+}
+
+func TestCountingSort(t *testing.T) {
+	ages := make([]int, 1000)
+	for i := range ages {
+		ages[i] = rand.IntN(100) - 10
+	}
+	checkRadixSort(t, "小范围", ages, CountingSort[int])
+
+	// 取值范围超过 countingSortMaxRange 或元素数的 4 倍时退化为基数排序
+	wide := make([]int64, 1000)
+	for i := range wide {
+		wide[i] = rand.Int64N(1<<40) - 1<<39
+	}
+	wide[0], wide[1] = math.MinInt64, math.MaxInt64
+	checkRadixSort(t, "超出计数范围", wide, CountingSort[int64])
+	sparse := []uint8{200, 3, 0, 255, 3}
+	checkRadixSort(t, "超过元素数 4 倍", sparse, CountingSort[uint8])
+	checkRadixSort(t, "单个元素", []int{7}, CountingSort[int])
+	checkRadixSort(t, "空切片", []int{}, CountingSort[int])
+}
+
+// checkRadixSort 检查 sort 的结果与 slices.Sort 一致
+func checkRadixSort[T cmp.Ordered](t *testing.T, name string, s []T, sort func([]T)) {
+	t.Helper()
+	got := slices.Clone(s)
+	sort(got)
+	want := slices.Clone(s)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: 结果与 slices.Sort 不一致", name)
+	}
+}
+
+// BenchmarkRadixSorts 对比专用排序与 slices.Sort 的性能
+func BenchmarkRadixSorts(b *testing.B) {
+	const n = 1 << 20
+	int64s := make([]int64, n)
+	uint32s := make([]uint32, n)
+	float64s := make([]float64, n)
+	ages := make([]int, n)
+	for i := range int64s {
+		int64s[i] = rand.Int64() - math.MaxInt64/2
+		uint32s[i] = rand.Uint32()
+		float64s[i] = rand.NormFloat64() * 1e6
+		ages[i] = rand.IntN(100)
+	}
+	strs := make([]string, n/4)
+	for i := range strs {
+		strs[i] = fmt.Sprintf("user-%x-%d", rand.Uint32(), rand.IntN(1000))
+	}
+
+	b.Run("int64/slices.Sort", func(b *testing.B) { benchmarkOnCopy(b, int64s, slices.Sort[[]int64]) })
+	b.Run("int64/RadixSortInts", func(b *testing.B) { benchmarkOnCopy(b, int64s, RadixSortInts[int64]) })
+	b.Run("uint32/slices.Sort", func(b *testing.B) { benchmarkOnCopy(b, uint32s, slices.Sort[[]uint32]) })
+	b.Run("uint32/RadixSortInts", func(b *testing.B) { benchmarkOnCopy(b, uint32s, RadixSortInts[uint32]) })
+	b.Run("float64/slices.Sort", func(b *testing.B) { benchmarkOnCopy(b, float64s, slices.Sort[[]float64]) })
+	b.Run("float64/RadixSortFloats", func(b *testing.B) { benchmarkOnCopy(b, float64s, RadixSortFloats[float64]) })
+	b.Run("string/slices.Sort", func(b *testing.B) { benchmarkOnCopy(b, strs, slices.Sort[[]string]) })
+	b.Run("string/RadixSortStrings", func(b *testing.B) { benchmarkOnCopy(b, strs, RadixSortStrings) })
+	b.Run("age/slices.Sort", func(b *testing.B) { benchmarkOnCopy(b, ages, slices.Sort[[]int]) })
+	b.Run("age/CountingSort", func(b *testing.B) { benchmarkOnCopy(b, ages, CountingSort[int]) })
+}
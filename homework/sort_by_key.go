@@ -0,0 +1,102 @@
+// 预计算排序键（decorate-sort-undecorate）：每个元素的键只计算一次
+package main
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SortByKeyOptions SortByKey 的选项
+type SortByKeyOptions struct {
+	// Stable 键相等的元素保持原有相对顺序
+	Stable bool
+	// Parallel 分块并行计算键，适合键计算开销较大（解析日期、转换拼音、格式化等）且元素较多的场景
+	Parallel bool
+}
+
+// sortByKeyParallelChunk 并行计算键时每个协程至少处理的元素数
+const sortByKeyParallelChunk = 1024
+
+// SortByKey 按 key(x) 升序排序 s，每个元素的键只计算一次
+func SortByKey[T any, K cmp.Ordered](s []T, key func(T) K, opts SortByKeyOptions) {
+	SortByKeyFunc(s, key, cmp.Compare[K], opts)
+}
+
+// SortByKeyFunc 按 key(x) 和键的比较函数 compare 排序 s。
+// 先计算所有键并与原下标配对排序，再按排好的下标沿置换环原地移动 s 的元素，
+// 因此 key 调用 n 次而不是每次比较调用两次，额外空间为 n 个 (键, 下标) 对
+func SortByKeyFunc[T, K any](s []T, key func(T) K, compare func(a, b K) int, opts SortByKeyOptions) {
+	if len(s) < 2 {
+		return
+	}
+	type keyed struct {
+		key K
+		idx int
+	}
+	pairs := make([]keyed, len(s))
+	computeKeys(len(s), opts.Parallel, func(i int) {
+		pairs[i] = keyed{key(s[i]), i}
+	})
+
+	if opts.Stable {
+		// 键相等时按原下标比较，比 SortStableFunc 的插入+原地归并更快
+		slices.SortFunc(pairs, func(a, b keyed) int {
+			return cmp.Or(compare(a.key, b.key), cmp.Compare(a.idx, b.idx))
+		})
+	} else {
+		slices.SortFunc(pairs, func(a, b keyed) int {
+			return compare(a.key, b.key)
+		})
+	}
+
+	// 排序后位置 i 的元素应取自原位置 pairs[i].idx；逐个置换环移动，处理过的位置将 idx 置为自身
+	for i := range pairs {
+		if pairs[i].idx == i {
+			continue
+		}
+		saved := s[i]
+		j := i
+		for {
+			from := pairs[j].idx
+			pairs[j].idx = j
+			if from == i {
+				s[j] = saved
+				break
+			}
+			s[j] = s[from]
+			j = from
+		}
+	}
+}
+
+// computeKeys 对 0..n-1 调用 compute，parallel 且元素足够多时按 GOMAXPROCS 分块并行
+func computeKeys(n int, parallel bool, compute func(i int)) {
+	workers := min(runtime.GOMAXPROCS(0), n/sortByKeyParallelChunk)
+	if !parallel || workers < 2 {
+		for i := 0; i < n; i++ {
+			compute(i)
+		}
+		return
+	}
+	size := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += size {
+		hi := min(lo+size, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				compute(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// workYears 截至当前的工作年限
+func workYears(e BestPracticeEmployee) float64 {
+	return time.Since(e.HireDate).Hours() / (24 * 365)
+}
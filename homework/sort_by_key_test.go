@@ -0,0 +1,130 @@
+package main
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// randomEmployees 生成用于基准测试的员工
+func randomEmployees(n int) []BestPracticeEmployee {
+	surnames := []rune("张王李赵刘陈杨黄周吴徐孙胡朱高林何郭马罗")
+	given := []rune("伟芳娜敏静丽强磊军洋勇艳杰娟涛明超秀霞平刚")
+	departments := []string{"技术部", "销售部", "人事部", "财务部", "市场部"}
+	start := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	employees := make([]BestPracticeEmployee, n)
+	for i := range employees {
+		employees[i] = BestPracticeEmployee{
+			ID:         i + 1,
+			Name:       string([]rune{surnames[rand.IntN(len(surnames))], given[rand.IntN(len(given))], given[rand.IntN(len(given))]}),
+			Department: departments[rand.IntN(len(departments))],
+			Salary:     float64(5000 + rand.IntN(200)*100),
+			HireDate:   start.AddDate(0, 0, rand.IntN(14*365)),
+		}
+	}
+	return employees
+}
+
+func TestSortByKeyStableMatchesSortStableFunc(t *testing.T) {
+	// 保证单核环境下 Parallel 也会走分块并行计算键的路径
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	department := func(e BestPracticeEmployee) string { return e.Department }
+	salary := func(e BestPracticeEmployee) float64 { return e.Salary }
+	for _, n := range []int{0, 1, 2, 17, 3000, 5 * sortByKeyParallelChunk} {
+		employees := randomEmployees(n)
+		for _, parallel := range []bool{false, true} {
+			opts := SortByKeyOptions{Stable: true, Parallel: parallel}
+
+			// 部门只有 5 种取值，大量重复键检验置换环移动后相等元素仍保持原顺序
+			got := slices.Clone(employees)
+			SortByKey(got, department, opts)
+			want := slices.Clone(employees)
+			slices.SortStableFunc(want, By(department))
+			if !slices.Equal(employeeIDs(got), employeeIDs(want)) {
+				t.Errorf("n=%d parallel=%v: 按部门 SortByKey 与 SortStableFunc 结果不一致", n, parallel)
+			}
+
+			got = slices.Clone(employees)
+			SortByKey(got, salary, opts)
+			want = slices.Clone(employees)
+			slices.SortStableFunc(want, By(salary))
+			if !slices.Equal(employeeIDs(got), employeeIDs(want)) {
+				t.Errorf("n=%d parallel=%v: 按薪资 SortByKey 与 SortStableFunc 结果不一致", n, parallel)
+			}
+		}
+	}
+}
+
+func TestSortByKeyUnstable(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	employees := randomEmployees(4000)
+	for _, parallel := range []bool{false, true} {
+		calls := 0
+		var mu sync.Mutex
+		key := func(e BestPracticeEmployee) string {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return e.Department
+		}
+		got := slices.Clone(employees)
+		SortByKey(got, key, SortByKeyOptions{Parallel: parallel})
+		if calls != len(employees) {
+			t.Errorf("parallel=%v: 键函数调用 %d 次, 期望每个元素一次共 %d 次", parallel, calls, len(employees))
+		}
+		if !slices.IsSortedFunc(got, EmployeeByDepartment) {
+			t.Errorf("parallel=%v: 结果未按部门排序", parallel)
+		}
+		if !isPermutation(employeeIDs(got), employeeIDs(employees)) {
+			t.Errorf("parallel=%v: 结果不是输入的排列", parallel)
+		}
+	}
+}
+
+func TestSortByKeyFuncDescending(t *testing.T) {
+	s := []string{"b", "a", "c", "a"}
+	SortByKeyFunc(s, func(v string) string { return v }, func(a, b string) int { return cmp.Compare(b, a) }, SortByKeyOptions{})
+	if want := []string{"c", "b", "a", "a"}; !slices.Equal(s, want) {
+		t.Errorf("降序 SortByKeyFunc = %v, 期望 %v", s, want)
+	}
+}
+
+func employeeIDs(employees []BestPracticeEmployee) []int {
+	ids := make([]int, len(employees))
+	for i, e := range employees {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// BenchmarkSortByKey 对比每次比较都计算键与预计算键的耗时和内存分配
+func BenchmarkSortByKey(b *testing.B) {
+	employees := randomEmployees(1 << 16)
+	pinyin := func(e BestPracticeEmployee) string { return PinyinCollator.Pinyin(e.Name) }
+
+	b.Run("WorkYears/EachCompare", func(b *testing.B) {
+		benchmarkOnCopy(b, employees, func(s []BestPracticeEmployee) { slices.SortFunc(s, By(workYears)) })
+	})
+	b.Run("Pinyin/EachCompare", func(b *testing.B) {
+		benchmarkOnCopy(b, employees, func(s []BestPracticeEmployee) { slices.SortFunc(s, By(pinyin)) })
+	})
+	for _, opt := range []struct {
+		name string
+		opts SortByKeyOptions
+	}{
+		{"SortByKey", SortByKeyOptions{}},
+		{"SortByKeyStable", SortByKeyOptions{Stable: true}},
+		{"SortByKeyParallel", SortByKeyOptions{Parallel: true}},
+	} {
+		b.Run("WorkYears/"+opt.name, func(b *testing.B) {
+			benchmarkOnCopy(b, employees, func(s []BestPracticeEmployee) { SortByKey(s, workYears, opt.opts) })
+		})
+		b.Run("Pinyin/"+opt.name, func(b *testing.B) {
+			benchmarkOnCopy(b, employees, func(s []BestPracticeEmployee) { SortByKey(s, pinyin, opt.opts) })
+		})
+	}
+}
@@ -0,0 +1,366 @@
+// 声明式排序规则：在运行时把 "department asc, salary desc" 这样的字符串编译为比较器
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSortSpec 排序规则无法编译，具体原因见包装它的错误信息
+var ErrInvalidSortSpec = errors.New("无效的排序规则")
+
+// sortSpecKey 已编译排序规则的缓存键
+type sortSpecKey struct {
+	typ  reflect.Type
+	spec string
+}
+
+var (
+	// sortSpecCache 缓存已编译的比较器，键为 (类型, 规范化后的规则)
+	sortSpecCache sync.Map
+	// sortFieldCache 缓存结构体类型的可排序字段：字段名（小写）到字段下标路径
+	sortFieldCache sync.Map
+)
+
+// CompileSortSpec 将排序规则编译为类型 T（结构体或结构体指针）的比较器，结果按类型和规则缓存。
+//
+// 规则由逗号分隔的若干项组成，每项为 "字段路径 [asc|desc]"，方向缺省为 asc，例如
+// "department asc, salary desc, hiredate"。字段名不区分大小写，优先匹配 `sort:"name"` 标签，
+// 标签为 "-" 的字段不可用于排序；嵌套字段用点号连接（如 "employee.department"），
+// 嵌入结构体（包括未导出类型的嵌入结构体）的导出字段可直接引用。
+// 支持整数、浮点数、字符串、布尔、time.Time 及带有 Compare(T) int 方法的类型，以及指向它们的指针；
+// 路径上遇到 nil 指针时该值视为缺失，升序时排在最前
+func CompileSortSpec[T any](spec string) (Comparator[T], error) {
+	typ := reflect.TypeFor[T]()
+	terms, err := parseSortSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	key := sortSpecKey{typ: typ, spec: formatSortTerms(terms)}
+	if cached, ok := sortSpecCache.Load(key); ok {
+		return cached.(Comparator[T]), nil
+	}
+
+	root := typ
+	if root.Kind() == reflect.Pointer {
+		root = root.Elem()
+	}
+	if root.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: 类型 %v 不是结构体或结构体指针", ErrInvalidSortSpec, typ)
+	}
+
+	keys := make([]fieldComparator, len(terms))
+	for i, term := range terms {
+		fc, err := compileSortTerm(root, term)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = fc
+	}
+
+	comparator := Comparator[T](func(a, b T) int {
+		va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+		for _, k := range keys {
+			if r := k.compare(va, vb); r != 0 {
+				return r
+			}
+		}
+		return 0
+	})
+	actual, _ := sortSpecCache.LoadOrStore(key, comparator)
+	return actual.(Comparator[T]), nil
+}
+
+// MustCompileSortSpec 与 CompileSortSpec 相同，规则无效时 panic，适用于固定在代码中的规则
+func MustCompileSortSpec[T any](spec string) Comparator[T] {
+	c, err := CompileSortSpec[T](spec)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// sortTerm 排序规则中的一项
+type sortTerm struct {
+	path []string
+	desc bool
+}
+
+func parseSortSpec(spec string) ([]sortTerm, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, fmt.Errorf("%w: 规则为空", ErrInvalidSortSpec)
+	}
+	var terms []sortTerm
+	for i, item := range strings.Split(spec, ",") {
+		fields := strings.Fields(item)
+		switch {
+		case len(fields) == 0:
+			return nil, fmt.Errorf("%w: 第 %d 项为空", ErrInvalidSortSpec, i+1)
+		case len(fields) > 2:
+			return nil, fmt.Errorf("%w: 第 %d 项 %q 应为 \"字段 [asc|desc]\"", ErrInvalidSortSpec, i+1, strings.TrimSpace(item))
+		}
+		term := sortTerm{path: strings.Split(strings.ToLower(fields[0]), ".")}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				term.desc = true
+			default:
+				return nil, fmt.Errorf("%w: 第 %d 项的排序方向 %q 无效，应为 asc 或 desc", ErrInvalidSortSpec, i+1, fields[1])
+			}
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// formatSortTerms 规范化的规则文本，用作缓存键
+func formatSortTerms(terms []sortTerm) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		dir := "asc"
+		if term.desc {
+			dir = "desc"
+		}
+		parts[i] = strings.Join(term.path, ".") + " " + dir
+	}
+	return strings.Join(parts, ",")
+}
+
+// fieldComparator 比较两个根值在某个字段路径上的值
+type fieldComparator struct {
+	steps   [][]int
+	compare func(a, b reflect.Value) int
+}
+
+func compileSortTerm(root reflect.Type, term sortTerm) (fieldComparator, error) {
+	var steps [][]int
+	typ := root
+	for i, name := range term.path {
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return fieldComparator{}, fmt.Errorf("%w: %q 的类型 %v 不是结构体，无法访问字段 %q",
+				ErrInvalidSortSpec, strings.Join(term.path[:i], "."), typ, name)
+		}
+		fields := sortableFields(typ)
+		index, ok := fields[name]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for n := range fields {
+				names = append(names, n)
+			}
+			if len(names) == 0 {
+				return fieldComparator{}, fmt.Errorf("%w: 类型 %v 没有可用于排序的字段，无法访问 %q",
+					ErrInvalidSortSpec, typ, name)
+			}
+			sort.Strings(names)
+			return fieldComparator{}, fmt.Errorf("%w: 类型 %v 没有字段 %q（可用字段: %s）",
+				ErrInvalidSortSpec, typ, name, strings.Join(names, ", "))
+		}
+		steps = append(steps, index)
+		typ = typ.FieldByIndex(index).Type
+	}
+
+	compare, ok := valueComparator(typ)
+	if !ok {
+		return fieldComparator{}, fmt.Errorf("%w: 字段 %q 的类型 %v 不可比较",
+			ErrInvalidSortSpec, strings.Join(term.path, "."), typ)
+	}
+	fc := fieldComparator{steps: steps}
+	fc.compare = func(a, b reflect.Value) int {
+		va, okA := fc.resolve(a)
+		vb, okB := fc.resolve(b)
+		var r int
+		switch {
+		case !okA || !okB:
+			r = cmp.Compare(btoi(okA), btoi(okB))
+		default:
+			r = compare(va, vb)
+		}
+		if term.desc {
+			return -r
+		}
+		return r
+	}
+	return fc, nil
+}
+
+// resolve 沿字段路径取值，路径上遇到 nil 指针时返回 false
+func (fc fieldComparator) resolve(v reflect.Value) (reflect.Value, bool) {
+	for _, index := range fc.steps {
+		for _, i := range index {
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+			v = v.Field(i)
+		}
+	}
+	return v, true
+}
+
+// sortableFields 返回结构体类型可用于排序的字段（带缓存）。嵌入结构体的字段被提升，但不覆盖外层声明的同名字段
+func sortableFields(t reflect.Type) map[string][]int {
+	if cached, ok := sortFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	collectSortableFields(t, nil, fields, map[reflect.Type]bool{t: true})
+	actual, _ := sortFieldCache.LoadOrStore(t, fields)
+	return actual.(map[string][]int)
+}
+
+func collectSortableFields(t reflect.Type, prefix []int, fields map[string][]int, visiting map[reflect.Type]bool) {
+	type embeddedField struct {
+		typ   reflect.Type
+		index []int
+	}
+	var embedded []embeddedField
+	// declared 本层声明的字段名，即使改名或不可排序也会遮蔽嵌入结构体中的同名字段
+	declared := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.Anonymous {
+			declared[strings.ToLower(f.Name)] = true
+		}
+		tag := f.Tag.Get("sort")
+		if tag == "-" {
+			continue
+		}
+		index := append(slices.Clone(prefix), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		// 与 Go 的字段提升一致，未导出类型的嵌入结构体中的导出字段同样可以直接引用
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, embeddedField{ft, index})
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = index
+	}
+	for _, e := range embedded {
+		if visiting[e.typ] {
+			continue
+		}
+		visiting[e.typ] = true
+		promoted := make(map[string][]int)
+		collectSortableFields(e.typ, e.index, promoted, visiting)
+		for name, index := range promoted {
+			if _, exists := fields[name]; !exists && !declared[name] {
+				fields[name] = index
+			}
+		}
+	}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// valueComparator 返回类型 t 的值的三路比较函数，t 不可比较时返回 false
+func valueComparator(t reflect.Type) (func(a, b reflect.Value) int, bool) {
+	if t == timeType {
+		return func(a, b reflect.Value) int {
+			return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+		}, true
+	}
+	if m, ok := t.MethodByName("Compare"); ok &&
+		m.Type.NumIn() == 2 && m.Type.In(1) == t && m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Int {
+		return func(a, b reflect.Value) int {
+			return int(m.Func.Call([]reflect.Value{a, b})[0].Int())
+		}, true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Int(), b.Int()) }, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Uint(), b.Uint()) }, true
+	case reflect.Float32, reflect.Float64:
+		return func(a, b reflect.Value) int { return cmp.Compare(a.Float(), b.Float()) }, true
+	case reflect.String:
+		return func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) }, true
+	case reflect.Bool:
+		return func(a, b reflect.Value) int { return cmp.Compare(btoi(a.Bool()), btoi(b.Bool())) }, true
+	case reflect.Pointer:
+		elem, ok := valueComparator(t.Elem())
+		if !ok {
+			return nil, false
+		}
+		return func(a, b reflect.Value) int {
+			if a.IsNil() || b.IsNil() {
+				return cmp.Compare(btoi(!a.IsNil()), btoi(!b.IsNil()))
+			}
+			return elem(a.Elem(), b.Elem())
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// demonstrateSortSpec 用运行时的排序规则字符串代替手写比较函数
+func demonstrateSortSpec() {
+	fmt.Println("3.1 声明式排序规则:")
+	fmt.Println()
+
+	employees := []BestPracticeEmployee{
+		{1, "张三", "技术部", 8000, time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC), []string{"Go", "Python"}},
+		{2, "李四", "技术部", 8000, time.Date(2019, 3, 20, 0, 0, 0, 0, time.UTC), []string{"Java", "Spring"}},
+		{3, "王五", "销售部", 6000, time.Date(2021, 7, 10, 0, 0, 0, 0, time.UTC), []string{"销售", "客户"}},
+		{4, "赵六", "销售部", 6000, time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC), []string{"市场", "推广"}},
+		{5, "钱七", "技术部", 12000, time.Date(2018, 7, 10, 0, 0, 0, 0, time.UTC), []string{"架构", "管理"}},
+	}
+	spec := "department asc, salary desc, hiredate asc"
+	bySpec, err := CompileSortSpec[BestPracticeEmployee](spec)
+	if err != nil {
+		fmt.Printf("  编译失败: %v\n", err)
+		return
+	}
+	slices.SortFunc(employees, bySpec)
+	fmt.Printf("  规则 %q:\n", spec)
+	printBestPracticeEmployees(employees)
+
+	// 嵌套字段路径和 sort 标签
+	type reviewRow struct {
+		Employee *BestPracticeEmployee
+		Score    float64 `sort:"score"`
+		Comment  string  `sort:"-"`
+	}
+	rows := []reviewRow{
+		{&employees[0], 4.5, ""}, {nil, 3.0, "离职"}, {&employees[3], 4.5, ""}, {&employees[1], 3.8, ""},
+	}
+	slices.SortFunc(rows, MustCompileSortSpec[reviewRow]("score desc, employee.name"))
+	fmt.Println("  规则 \"score desc, employee.name\"（员工为 nil 的行按缺失值处理）:")
+	for _, row := range rows {
+		name := "<nil>"
+		if row.Employee != nil {
+			name = row.Employee.Name
+		}
+		fmt.Printf("  %s - %.1f\n", name, row.Score)
+	}
+	fmt.Println()
+
+	fmt.Println("  无效规则的错误信息:")
+	for _, bad := range []string{"age desc", "salary up", "skills", "department,,salary", "hiredate.year"} {
+		_, err := CompileSortSpec[BestPracticeEmployee](bad)
+		fmt.Printf("    %q: %v\n", bad, err)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// specPriority 自带 Compare 方法的类型，数值大的优先级高、排在前面
+type specPriority int
+
+func (p specPriority) Compare(other specPriority) int { return cmp.Compare(other, p) }
+
+type specAudit struct {
+	Created time.Time
+	Name    string // 被外层同名字段覆盖
+}
+
+type specMeta struct {
+	Region string
+}
+
+type specOwner struct {
+	Name string
+}
+
+type specRow struct {
+	specAudit
+	*specMeta
+	Name     string `sort:"title"`
+	Secret   string `sort:"-"`
+	Priority specPriority
+	Score    *float64
+	Owner    *specOwner
+	Tags     []string
+	hidden   int
+}
+
+func specIDs(rows []specRow) []string {
+	ids := make([]string, len(rows))
+	for i, r := range rows {
+		ids[i] = r.Name
+	}
+	return ids
+}
+
+func TestSortSpecParseErrors(t *testing.T) {
+	for _, spec := range []string{"", "   ", "title,,priority", "title,", "title up", "title asc extra", ", title"} {
+		if _, err := CompileSortSpec[specRow](spec); !errors.Is(err, ErrInvalidSortSpec) {
+			t.Errorf("CompileSortSpec(%q) 错误 = %v, 期望 ErrInvalidSortSpec", spec, err)
+		}
+	}
+}
+
+func TestSortSpecFieldErrors(t *testing.T) {
+	for _, spec := range []string{
+		"name",          // 标签改名后只能用 title 引用
+		"secret",        // sort:"-"
+		"hidden",        // 未导出字段
+		"tags",          // 切片不可比较
+		"missing desc",  // 不存在的字段
+		"title.length",  // 字符串不是结构体
+		"created.year",  // time.Time 没有可排序的字段
+		"owner.missing", // 嵌套路径中不存在的字段
+	} {
+		if _, err := CompileSortSpec[specRow](spec); !errors.Is(err, ErrInvalidSortSpec) {
+			t.Errorf("CompileSortSpec(%q) 错误 = %v, 期望 ErrInvalidSortSpec", spec, err)
+		}
+	}
+	if _, err := CompileSortSpec[int]("value"); !errors.Is(err, ErrInvalidSortSpec) {
+		t.Errorf("非结构体类型的错误 = %v, 期望 ErrInvalidSortSpec", err)
+	}
+}
+
+func TestSortSpecOrdering(t *testing.T) {
+	score := func(f float64) *float64 { return &f }
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	rows := []specRow{
+		{Name: "a", Priority: 1, Score: score(3), Owner: &specOwner{"zoe"}, specAudit: specAudit{day(3), "x"}, specMeta: &specMeta{"north"}},
+		{Name: "b", Priority: 3, Score: nil, Owner: nil, specAudit: specAudit{day(1), "y"}},
+		{Name: "c", Priority: 2, Score: score(1), Owner: &specOwner{"amy"}, specAudit: specAudit{day(2), "z"}, specMeta: &specMeta{"east"}},
+		{Name: "d", Priority: 3, Score: score(2), Owner: &specOwner{"bob"}, specAudit: specAudit{day(1), "w"}, specMeta: &specMeta{"south"}},
+	}
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"title desc", []string{"d", "c", "b", "a"}},
+		{"priority", []string{"b", "d", "c", "a"}},                  // Compare 方法：数值大的在前
+		{"priority desc, title desc", []string{"a", "c", "d", "b"}}, // desc 反转 Compare 方法的结果
+		{"created, title", []string{"b", "d", "c", "a"}},            // 嵌入结构体的 time.Time 字段
+		{"score", []string{"b", "c", "d", "a"}},                     // nil 指针视为缺失，升序在前
+		{"score desc", []string{"a", "d", "c", "b"}},                // 降序时缺失值在后
+		{"owner.name", []string{"b", "c", "d", "a"}},                // 路径上的 nil 指针视为缺失
+		{"owner.name desc", []string{"a", "d", "c", "b"}},
+		{"region", []string{"b", "c", "a", "d"}}, // 未导出类型的嵌入结构体指针，nil 视为缺失
+	}
+	for _, tt := range tests {
+		by, err := CompileSortSpec[specRow](tt.spec)
+		if err != nil {
+			t.Errorf("CompileSortSpec(%q): %v", tt.spec, err)
+			continue
+		}
+		got := slices.Clone(rows)
+		slices.SortStableFunc(got, by)
+		if ids := specIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("%q 排序结果 = %v, 期望 %v", tt.spec, ids, tt.want)
+		}
+	}
+
+	// 结构体指针同样可以使用
+	ptrs := []*specRow{&rows[0], &rows[1], &rows[2], &rows[3]}
+	slices.SortFunc(ptrs, MustCompileSortSpec[*specRow]("owner.name desc"))
+	if ptrs[0].Name != "a" || ptrs[3].Name != "b" {
+		t.Errorf("结构体指针排序结果首尾 = %s, %s, 期望 a, b", ptrs[0].Name, ptrs[3].Name)
+	}
+}
+
+// 外层字段覆盖嵌入结构体的同名字段，与 Go 的字段提升规则一致
+func TestSortSpecEmbeddedShadowing(t *testing.T) {
+	type inner struct{ Level, Extra int }
+	type outer struct {
+		inner
+		Level string
+	}
+	rows := []outer{{inner{1, 2}, "b"}, {inner{2, 1}, "a"}}
+	slices.SortFunc(rows, MustCompileSortSpec[outer]("level"))
+	if rows[0].Level != "a" {
+		t.Errorf("level 应引用外层的字符串字段, 排序结果 = %v", rows)
+	}
+	slices.SortFunc(rows, MustCompileSortSpec[outer]("extra"))
+	if rows[0].Extra != 1 {
+		t.Errorf("extra 应引用嵌入结构体的字段, 排序结果 = %v", rows)
+	}
+}
+
+// 规范化后相同的规则命中缓存，不会重复编译
+func TestSortSpecCache(t *testing.T) {
+	type cachedRow struct{ Title, Priority int }
+	cached := func() int {
+		n := 0
+		sortSpecCache.Range(func(k, _ any) bool {
+			if k.(sortSpecKey).typ == reflect.TypeFor[cachedRow]() {
+				n++
+			}
+			return true
+		})
+		return n
+	}
+
+	MustCompileSortSpec[cachedRow]("Title ASC ,  priority")
+	MustCompileSortSpec[cachedRow]("title,priority asc")
+	if n := cached(); n != 1 {
+		t.Errorf("规范化后相同的两条规则缓存了 %d 个比较器, 期望 1 个", n)
+	}
+	MustCompileSortSpec[cachedRow]("title desc, priority")
+	if n := cached(); n != 2 {
+		t.Errorf("方向不同的规则缓存了 %d 个比较器, 期望 2 个", n)
+	}
+}
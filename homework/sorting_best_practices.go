@@ -2,10 +2,11 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"sort"
-	"strconv"
-
+	"strings"
 	"time"
 )
 
@@ -41,9 +42,18 @@ func DemonstrateSortingBestPractices() {
 	// 3. 多级排序策略
 	demonstrateMultiLevelSorting()
 
+	// 3.1 声明式排序规则
+	demonstrateSortSpec()
+
 	// 4. 字符串自然排序
 	demonstrateNaturalStringSorting()
 
+	// 4.1 中文姓名排序
+	demonstrateHanCollation()
+
+	// 4.2 版本号排序
+	demonstrateVersionSorting()
+
 	// 5. 排序稳定性的实际应用
 	demonstrateSortingStabilityUsage()
 
@@ -85,28 +95,16 @@ func demonstratePrecomputedKeys() {
 	fmt.Println("方法1 - 直接排序（按工作年限降序）:")
 	printBestPracticeEmployees(employees1)
 
-	// 方法2: 预计算排序键（推荐）
-	type EmployeeWithKey struct {
-		BestPracticeEmployee
-		WorkYears float64
-	}
-
-	employeesWithKeys := make([]EmployeeWithKey, len(employees))
-	for i, emp := range employees {
-		employeesWithKeys[i] = EmployeeWithKey{
-			BestPracticeEmployee: emp,
-			WorkYears:            time.Since(emp.HireDate).Hours() / (24 * 365),
-		}
-	}
-
-	sort.Slice(employeesWithKeys, func(i, j int) bool {
-		return employeesWithKeys[i].WorkYears > employeesWithKeys[j].WorkYears
-	})
+	// 方法2: 预计算排序键（推荐），每个员工的工作年限只计算一次
+	employees2 := slices.Clone(employees)
+	SortByKeyFunc(employees2, workYears, func(a, b float64) int {
+		return cmp.Compare(b, a) // 按工作年限降序
+	}, SortByKeyOptions{Stable: true})
 
 	fmt.Println("方法2 - 预计算排序键（推荐）:")
-	for _, empWithKey := range employeesWithKeys {
+	for _, emp := range employees2 {
 		fmt.Printf("  %s (%.1f年) - %s - ¥%.0f\n",
-			empWithKey.Name, empWithKey.WorkYears, empWithKey.Department, empWithKey.Salary)
+			emp.Name, workYears(emp), emp.Department, emp.Salary)
 	}
 	fmt.Println()
 }
@@ -127,38 +125,49 @@ func demonstrateComplexBusinessSorting() {
 	fmt.Println("原始订单:")
 	printBestPracticeOrders(orders)
 
-	// 复杂业务排序规则:
-	// 1. urgent状态优先级最高
-	// 2. 然后按优先级数字排序（1最高）
-	// 3. 相同优先级按金额降序
-	// 4. 相同金额按时间升序
-	sort.Slice(orders, func(i, j int) bool {
-		orderI, orderJ := orders[i], orders[j]
-
-		// 1. urgent状态优先
-		if orderI.Status == "urgent" && orderJ.Status != "urgent" {
-			return true
-		}
-		if orderI.Status != "urgent" && orderJ.Status == "urgent" {
-			return false
-		}
-
-		// 2. 按优先级排序
-		if orderI.Priority != orderJ.Priority {
-			return orderI.Priority < orderJ.Priority
-		}
-
-		// 3. 按金额降序
-		if orderI.Amount != orderJ.Amount {
-			return orderI.Amount > orderJ.Amount
+	// 业务排序规则来自 JSON 配置（默认见 order_rules.json，可用 -order-rules 指定文件）:
+	// urgent 状态优先，然后按优先级数字（1最高）、金额降序、下单时间升序
+	engine, source := DefaultOrderRuleEngine(), "内置默认规则"
+	if orderRulesPath != "" {
+		if loaded, err := LoadOrderRulesFile(orderRulesPath); err != nil {
+			fmt.Printf("❌ %v，改用内置默认规则\n", err)
+		} else {
+			engine, source = loaded, orderRulesPath
 		}
+	}
+	slices.SortStableFunc(orders, engine.Compare)
 
-		// 4. 按时间升序
-		return orderI.Date.Before(orderJ.Date)
-	})
+	fmt.Printf("复杂业务逻辑排序后 (%s):\n", source)
+	printBestPracticeOrders(orders)
+	explainOrderRanking(engine, orders)
+
+	// 运营调整规则只需修改配置：细分状态等级，并对优先级、金额和等待时长加权评分
+	weighted, err := LoadOrderRules(strings.NewReader(`{
+		"statusRank": {"urgent": 0, "processing": 1, "pending": 1, "completed": 9},
+		"defaultStatusRank": 5,
+		"scoring": [
+			{"field": "priority", "weight": -100},
+			{"field": "amount", "weight": 0.05, "cap": 100},
+			{"field": "ageHours", "weight": 1, "cap": 72}
+		],
+		"tieBreakers": "date asc, id asc"
+	}`))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	weighted = weighted.At(time.Date(2023, 12, 3, 12, 0, 0, 0, time.UTC))
+	slices.SortStableFunc(orders, weighted.Compare)
 
-	fmt.Println("复杂业务逻辑排序后:")
+	fmt.Println("加权评分规则排序后:")
 	printBestPracticeOrders(orders)
+	explainOrderRanking(weighted, orders)
+
+	// 配置错误会在加载时报告
+	if _, err := LoadOrderRules(strings.NewReader(`{"scoring": [{"field": "discount", "weight": 1}]}`)); err != nil {
+		fmt.Printf("错误的规则配置: %v\n", err)
+		fmt.Println()
+	}
 }
 
 // 3. 多级排序策略
@@ -178,25 +187,35 @@ func demonstrateMultiLevelSorting() {
 	printBestPracticeEmployees(employees)
 
 	// 多级排序: 部门 -> 薪资(降序) -> 入职时间(升序)
-	sort.Slice(employees, func(i, j int) bool {
-		empI, empJ := employees[i], employees[j]
-
-		// 第一级: 按部门排序
-		if empI.Department != empJ.Department {
-			return empI.Department < empJ.Department
-		}
-
-		// 第二级: 按薪资降序
-		if empI.Salary != empJ.Salary {
-			return empI.Salary > empJ.Salary
-		}
-
-		// 第三级: 按入职时间升序
-		return empI.HireDate.Before(empJ.HireDate)
-	})
+	slices.SortFunc(employees, EmployeeByDepartment.
+		Then(EmployeeBySalary.Reverse()).
+		Then(EmployeeByHireDate))
 
 	fmt.Println("多级排序后 (部门 -> 薪资降序 -> 入职时间升序):")
 	printBestPracticeEmployees(employees)
+
+	// 指针切片中的 nil 元素和未填写的入职日期（零值）统一排在最后
+	pointers := []*BestPracticeEmployee{
+		&employees[0],
+		nil,
+		{6, "孙八", "技术部", 9000, time.Time{}, nil},
+		&employees[3],
+	}
+	slices.SortStableFunc(pointers, NilsLast(EmployeeByHireDate))
+
+	fmt.Println("含 nil 和零值入职日期的指针切片 (入职时间升序):")
+	for _, emp := range pointers {
+		if emp == nil {
+			fmt.Println("  <nil>")
+			continue
+		}
+		hireDate := "未填写"
+		if !emp.HireDate.IsZero() {
+			hireDate = emp.HireDate.Format("2006-01-02")
+		}
+		fmt.Printf("  %s - %s\n", emp.Name, hireDate)
+	}
+	fmt.Println()
 }
 
 // 4. 字符串自然排序
@@ -235,6 +254,17 @@ func demonstrateNaturalStringSorting() {
 	for _, file := range files2 {
 		fmt.Printf("  %s\n", file)
 	}
+
+	// 边界情况：超长数字、前导零、大小写、全角和其他书写系统的数字
+	tricky := []string{
+		"file01.txt", "File2.txt", "file1.txt", "ｆｉｌｅ３.txt", "file１２.txt",
+		"file١١.txt", "file99999999999999999999.txt", "file100000000000000000000.txt", "file2.txt",
+	}
+	slices.SortFunc(tricky, NaturalCompare)
+	fmt.Println("\n自然排序边界情况（忽略大小写和全半角）:")
+	for _, file := range tricky {
+		fmt.Printf("  %s\n", file)
+	}
 	fmt.Println()
 }
 
@@ -308,17 +338,24 @@ func demonstrateLargeDataSorting() {
 	fmt.Println("  3. 选择合适的排序算法:")
 	fmt.Println("     - 基础类型使用专用函数")
 	fmt.Println("     - 不需要稳定性时避免使用 SliceStable")
-	fmt.Println()
+	fmt.Println("     - 整数、浮点数和字符串可用基数排序，小范围整数可用计数排序")
+	demonstrateRadixSorts()
 
 	fmt.Println("  4. 考虑并行处理:")
 	fmt.Println("     - 分块排序后合并")
 	fmt.Println("     - 使用 goroutine 并行处理")
-	fmt.Println()
+	demonstrateParallelSort()
 
 	fmt.Println("  5. 内存优化:")
 	fmt.Println("     - 原地排序 vs 复制排序")
 	fmt.Println("     - 及时释放不需要的内存")
-	fmt.Println()
+	fmt.Println("     - 超出内存的数据使用外部排序")
+	demonstrateExternalSort()
+
+	fmt.Println("  6. 只需要部分结果时避免完整排序:")
+	fmt.Println("     - 前 k 个用堆选择，O(n log k)")
+	fmt.Println("     - 中位数、分位数用快速选择，O(n)")
+	demonstrateTopK()
 }
 
 // 7. 排序错误处理和边界情况
@@ -377,46 +414,9 @@ func printBestPracticeOrders(orders []BestPracticeOrder) {
 }
 
 // 自然排序比较函数
+// 数字部分按数值比较，忽略大小写和全半角差异，详见 NaturalCollator
 func naturalLess(a, b string) bool {
-	// 简化的自然排序实现
-	// 实际项目中可能需要更复杂的实现
-
-	i, j := 0, 0
-	for i < len(a) && j < len(b) {
-		// 如果都是数字，按数值比较
-		if isDigit(a[i]) && isDigit(b[j]) {
-			numA, nextI := extractNumber(a, i)
-			numB, nextJ := extractNumber(b, j)
-
-			if numA != numB {
-				return numA < numB
-			}
-			i, j = nextI, nextJ
-		} else {
-			// 按字符比较
-			if a[i] != b[j] {
-				return a[i] < b[j]
-			}
-			i++
-			j++
-		}
-	}
-
-	return len(a) < len(b)
-}
-
-func isDigit(c byte) bool {
-	return c >= '0' && c <= '9'
-}
-
-func extractNumber(s string, start int) (int, int) {
-	end := start
-	for end < len(s) && isDigit(s[end]) {
-		end++
-	}
-
-	num, _ := strconv.Atoi(s[start:end])
-	return num, end
+	return NaturalCompare(a, b) < 0
 }
 
 /*
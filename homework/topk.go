@@ -0,0 +1,227 @@
+// 部分排序与选择：Top-K、第 n 小元素和只排前 k 个元素
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// TopK 返回 s 中按 cmp 最大的 k 个元素，按降序排列；相等的元素保持在 s 中的先后顺序。
+// 不修改 s，使用大小为 k 的堆，时间 O(n log k)，额外空间 O(k)
+func TopK[T any](s []T, k int, cmp func(a, b T) int) []T {
+	return TopKSeq(slices.Values(s), k, cmp)
+}
+
+// BottomK 返回 s 中按 cmp 最小的 k 个元素，按升序排列；相等的元素保持在 s 中的先后顺序
+func BottomK[T any](s []T, k int, cmp func(a, b T) int) []T {
+	return BottomKSeq(slices.Values(s), k, cmp)
+}
+
+// TopKSeq 流式版本的 TopK：只遍历一次 seq，内存中最多保留 k 个元素，适合数据库游标、文件扫描等无法全部装入内存的数据源
+func TopKSeq[T any](seq iter.Seq[T], k int, cmp func(a, b T) int) []T {
+	if k <= 0 {
+		return nil
+	}
+	// 堆顶是已保留元素中最差的一个：值最小，值相等时最晚出现（来源记为负序号）。
+	// 新元素只有严格大于堆顶时才替换它，因此相等时先出现的元素优先保留
+	h := newMergeHeap(cmp)
+	i := 0
+	for v := range seq {
+		switch {
+		case h.len() < k:
+			h.push(v, -i)
+		default:
+			if top, _ := h.top(); cmp(v, top) > 0 {
+				h.replaceTop(v, -i)
+			}
+		}
+		i++
+	}
+	result := make([]T, h.len())
+	for j := len(result) - 1; j >= 0; j-- {
+		result[j], _ = h.pop()
+	}
+	return result
+}
+
+// BottomKSeq 流式版本的 BottomK
+func BottomKSeq[T any](seq iter.Seq[T], k int, cmp func(a, b T) int) []T {
+	return TopKSeq(seq, k, func(a, b T) int { return cmp(b, a) })
+}
+
+// NthElement 重排 s，使 s[n] 恰为完整排序后位于下标 n 的元素，s[:n] 中的元素都不大于它，s[n+1:] 中的元素都不小于它。
+// 采用三路划分的快速选择，平均 O(n)；划分轮数超过 2·log2(n) 时改用中位数的中位数选取枢轴（introselect），
+// 最坏情况仍为 O(n)。不保证稳定，n 越界时 panic
+func NthElement[T any](s []T, n int, cmp func(a, b T) int) {
+	if n < 0 || n >= len(s) {
+		panic(fmt.Sprintf("NthElement: 下标 %d 超出范围 [0, %d)", n, len(s)))
+	}
+	introselect(s, n, cmp, 2*bits.Len(uint(len(s))))
+}
+
+// PartialSort 重排 s，使 s[:k] 为最小的 k 个元素并按升序排列，s[k:] 的顺序不确定。
+// 先用 NthElement 选出前 k 个再排序，时间 O(n + k log k)
+func PartialSort[T any](s []T, k int, cmp func(a, b T) int) {
+	k = min(k, len(s))
+	if k <= 0 {
+		return
+	}
+	if k == len(s) {
+		slices.SortFunc(s, cmp)
+		return
+	}
+	// NthElement 之后 s[k-1] 已在最终位置，只需排序它之前的元素
+	NthElement(s, k-1, cmp)
+	slices.SortFunc(s[:k-1], cmp)
+}
+
+// introselect 在 s 中选出第 n 小的元素；budget 为剩余的快速选择轮数，用尽后每轮都用中位数的中位数作枢轴
+func introselect[T any](s []T, n int, cmp func(a, b T) int, budget int) {
+	lo, hi := 0, len(s)
+	for hi-lo > 12 {
+		var pivot T
+		if budget > 0 {
+			budget--
+			pivot = medianOfThree(s[lo], s[lo+(hi-lo)/2], s[hi-1], cmp)
+		} else {
+			pivot = medianOfMedians(s[lo:hi], cmp)
+		}
+		lt, gt := partition3(s[lo:hi], pivot, cmp)
+		switch {
+		case n < lo+lt:
+			hi = lo + lt
+		case n >= lo+gt:
+			lo += gt
+		default:
+			return // s[lo+lt:lo+gt] 都等于枢轴，n 落在其中
+		}
+	}
+	insertionSortFunc(s[lo:hi], cmp)
+}
+
+// partition3 按枢轴值三路划分：s[:lt] < pivot，s[lt:gt] == pivot，s[gt:] > pivot。
+// 等值区间整体排除，大量重复元素时不会退化
+func partition3[T any](s []T, pivot T, cmp func(a, b T) int) (lt, gt int) {
+	i, gt := 0, len(s)
+	for i < gt {
+		switch c := cmp(s[i], pivot); {
+		case c < 0:
+			s[lt], s[i] = s[i], s[lt]
+			lt++
+			i++
+		case c > 0:
+			gt--
+			s[i], s[gt] = s[gt], s[i]
+		default:
+			i++
+		}
+	}
+	return lt, gt
+}
+
+func medianOfThree[T any](a, b, c T, cmp func(a, b T) int) T {
+	if cmp(a, b) > 0 {
+		a, b = b, a
+	}
+	if cmp(b, c) > 0 {
+		b = c
+		if cmp(a, b) > 0 {
+			b = a
+		}
+	}
+	return b
+}
+
+// medianOfMedians 将 s 按 5 个一组取中位数并移到 s 的开头，再递归选出这些中位数的中位数。
+// 保证枢轴两侧至少各有约 3/10 的元素
+func medianOfMedians[T any](s []T, cmp func(a, b T) int) T {
+	groups := 0
+	for i := 0; i < len(s); i += 5 {
+		group := s[i:min(i+5, len(s))]
+		insertionSortFunc(group, cmp)
+		s[groups], group[len(group)/2] = group[len(group)/2], s[groups]
+		groups++
+	}
+	introselect(s[:groups], groups/2, cmp, 0)
+	return s[groups/2]
+}
+
+func insertionSortFunc[T any](s []T, cmp func(a, b T) int) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && cmp(s[j], s[j-1]) < 0; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+// randomOrders 生成用于演示和基准测试的订单
+func randomOrders(n int) []BestPracticeOrder {
+	statuses := []string{"pending", "processing", "urgent", "completed"}
+	start := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	orders := make([]BestPracticeOrder, n)
+	for i := range orders {
+		orders[i] = BestPracticeOrder{
+			ID:       fmt.Sprintf("ORD%06d", i+1),
+			Amount:   float64(rand.IntN(100000)) / 10,
+			Date:     start.Add(time.Duration(rand.IntN(30*24*60)) * time.Minute),
+			Priority: rand.IntN(3) + 1,
+			Status:   statuses[rand.IntN(len(statuses))],
+		}
+	}
+	return orders
+}
+
+// demonstrateTopK 展示只需要前 k 个结果时的选择算法
+func demonstrateTopK() {
+	fmt.Println("  Top-K 与部分排序:")
+	orders := randomOrders(100000)
+
+	top := TopK(orders, 5, OrderByAmount)
+	fmt.Println("     金额最高的 5 个订单 (TopK):")
+	for _, o := range top {
+		fmt.Printf("       %s ¥%.1f\n", o.ID, o.Amount)
+	}
+	sorted := slices.Clone(orders)
+	slices.SortStableFunc(sorted, OrderByAmount.Reverse())
+	fmt.Printf("     与完整稳定排序的前 5 个一致: %v\n", slices.EqualFunc(top, sorted[:5], func(a, b BestPracticeOrder) bool {
+		return a.ID == b.ID
+	}))
+
+	earliest := BottomK(orders, 3, OrderByDate)
+	fmt.Printf("     最早的 3 个订单 (BottomK): %s, %s, %s\n", earliest[0].ID, earliest[1].ID, earliest[2].ID)
+
+	amounts := make([]float64, len(orders))
+	for i, o := range orders {
+		amounts[i] = o.Amount
+	}
+	mid := len(amounts) / 2
+	NthElement(amounts, mid, cmp.Compare[float64])
+	fmt.Printf("     金额中位数 (NthElement): ¥%.1f\n", amounts[mid])
+
+	PartialSort(orders, 3, OrderUrgentFirst.Then(OrderByPriority).Then(OrderByAmount.Reverse()))
+	fmt.Println("     处理队列的前 3 个订单 (PartialSort):")
+	printOrderLines(orders[:3])
+
+	// 流式数据：逐个生成订单，内存中只保留 3 个
+	stream := func(yield func(BestPracticeOrder) bool) {
+		for _, o := range randomOrders(1000) {
+			if !yield(o) {
+				return
+			}
+		}
+	}
+	fmt.Println("     订单流中金额最高的 3 个 (TopKSeq):")
+	printOrderLines(TopKSeq(stream, 3, OrderByAmount))
+	fmt.Println()
+}
+
+func printOrderLines(orders []BestPracticeOrder) {
+	for _, o := range orders {
+		fmt.Printf("       %s ¥%.1f (优先级:%d, %s)\n", o.ID, o.Amount, o.Priority, o.Status)
+	}
+}
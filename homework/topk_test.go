@@ -0,0 +1,188 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// scored 带来源序号的测试元素，只按 score 比较，用于检查相等元素的先后顺序
+type scored struct {
+	score, seq int
+}
+
+func byScore(a, b scored) int { return cmp.Compare(a.score, b.score) }
+
+func TestTopKBottomK(t *testing.T) {
+	s := []scored{{3, 0}, {5, 1}, {1, 2}, {5, 3}, {3, 4}, {1, 5}, {5, 6}, {2, 7}}
+	tests := []struct {
+		name string
+		got  []scored
+		want []scored
+	}{
+		{"TopK 相等元素保持原顺序", TopK(s, 4, byScore), []scored{{5, 1}, {5, 3}, {5, 6}, {3, 0}}},
+		{"TopK 截断在相等元素中间", TopK(s, 2, byScore), []scored{{5, 1}, {5, 3}}},
+		{"BottomK 相等元素保持原顺序", BottomK(s, 3, byScore), []scored{{1, 2}, {1, 5}, {2, 7}}},
+		{"TopK k=0", TopK(s, 0, byScore), nil},
+		{"TopK k<0", TopK(s, -1, byScore), nil},
+		{"BottomK k=0", BottomK(s, 0, byScore), nil},
+		{"TopK k>len 返回全部降序", TopK(s, 100, byScore),
+			[]scored{{5, 1}, {5, 3}, {5, 6}, {3, 0}, {3, 4}, {2, 7}, {1, 2}, {1, 5}}},
+		{"BottomK k>len 返回全部升序", BottomK(s, 100, byScore),
+			[]scored{{1, 2}, {1, 5}, {2, 7}, {3, 0}, {3, 4}, {5, 1}, {5, 3}, {5, 6}}},
+		{"空输入", TopK([]scored{}, 3, byScore), []scored{}},
+	}
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %v, 期望 %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if !slices.Equal(s, []scored{{3, 0}, {5, 1}, {1, 2}, {5, 3}, {3, 4}, {1, 5}, {5, 6}, {2, 7}}) {
+		t.Error("TopK/BottomK 不应修改输入")
+	}
+}
+
+func TestTopKSeq(t *testing.T) {
+	orders := randomOrders(2000)
+	byAmount := OrderByAmount.Then(OrderByID)
+	want := slices.Clone(orders)
+	slices.SortStableFunc(want, byAmount.Reverse())
+
+	visited := 0
+	seq := func(yield func(BestPracticeOrder) bool) {
+		for _, o := range orders {
+			visited++
+			if !yield(o) {
+				return
+			}
+		}
+	}
+	got := TopKSeq(seq, 25, byAmount)
+	if visited != len(orders) {
+		t.Errorf("TopKSeq 遍历了 %d 个元素, 期望只遍历一次共 %d 个", visited, len(orders))
+	}
+	if !slices.Equal(orderIDs(got), orderIDs(want[:25])) {
+		t.Errorf("TopKSeq 结果与完整排序的前 25 个不一致")
+	}
+	ascending := slices.Clone(orders)
+	slices.SortStableFunc(ascending, byAmount)
+	if got := BottomKSeq(slices.Values(orders), 10, byAmount); !slices.Equal(orderIDs(got), orderIDs(ascending[:10])) {
+		t.Errorf("BottomKSeq 结果与完整排序的前 10 个不一致")
+	}
+}
+
+func TestNthElement(t *testing.T) {
+	inputs := map[string][]int{
+		"随机":   rand.Perm(5000),
+		"大量重复": make([]int, 5000),
+		"已排序":  make([]int, 5000),
+		"逆序":   make([]int, 5000),
+		"管风琴":  make([]int, 5001),
+		"小输入":  {5, 3, 9, 1},
+	}
+	for i := range inputs["大量重复"] {
+		inputs["大量重复"][i] = rand.IntN(4)
+		inputs["已排序"][i] = i
+		inputs["逆序"][i] = -i
+	}
+	for i := range inputs["管风琴"] {
+		inputs["管风琴"][i] = min(i, 5000-i)
+	}
+
+	for name, input := range inputs {
+		sorted := slices.Clone(input)
+		slices.Sort(sorted)
+		for _, n := range []int{0, 1, len(input) / 2, len(input) - 1} {
+			// budget 为 0 时每轮都使用中位数的中位数作枢轴，覆盖 introselect 的兜底路径
+			for _, budget := range []int{2 * bits.Len(uint(len(input))), 0} {
+				s := slices.Clone(input)
+				introselect(s, n, cmp.Compare[int], budget)
+				checkNthElement(t, fmt.Sprintf("%s/n=%d/budget=%d", name, n, budget), s, sorted, n)
+			}
+		}
+	}
+
+	s := []int{4, 1, 3}
+	NthElement(s, 1, cmp.Compare[int])
+	if s[1] != 3 {
+		t.Errorf("NthElement(s, 1) 后 s[1] = %d, 期望 3", s[1])
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NthElement 下标越界时应 panic")
+		}
+	}()
+	NthElement(s, 3, cmp.Compare[int])
+}
+
+// checkNthElement 检查 s[n] 等于完整排序后的 sorted[n]，且两侧划分正确、元素不丢失
+func checkNthElement(t *testing.T, name string, s, sorted []int, n int) {
+	t.Helper()
+	if s[n] != sorted[n] {
+		t.Errorf("%s: s[n] = %d, 期望 %d", name, s[n], sorted[n])
+		return
+	}
+	for i, v := range s {
+		if i < n && v > s[n] || i > n && v < s[n] {
+			t.Errorf("%s: 下标 %d 的 %d 位于错误的一侧", name, i, v)
+			return
+		}
+	}
+	if !isPermutation(s, sorted) {
+		t.Errorf("%s: 结果不是输入的排列", name)
+	}
+}
+
+func TestPartialSort(t *testing.T) {
+	input := make([]int, 3000)
+	for i := range input {
+		input[i] = rand.IntN(500)
+	}
+	sorted := slices.Clone(input)
+	slices.Sort(sorted)
+	for _, k := range []int{-1, 0, 1, 10, 1500, 2999, 3000, 5000} {
+		s := slices.Clone(input)
+		PartialSort(s, k, cmp.Compare[int])
+		prefix := max(0, min(k, len(s)))
+		if !slices.Equal(s[:prefix], sorted[:prefix]) {
+			t.Errorf("k=%d: 前 %d 个元素不是最小的 k 个并升序排列", k, prefix)
+		}
+		if !isPermutation(s, input) {
+			t.Errorf("k=%d: 结果不是输入的排列", k)
+		}
+	}
+}
+
+// isPermutation 判断 a 和 b 是否包含相同的元素（计重数）
+func isPermutation[T cmp.Ordered](a, b []T) bool {
+	x, y := slices.Clone(a), slices.Clone(b)
+	slices.Sort(x)
+	slices.Sort(y)
+	return slices.Equal(x, y)
+}
+
+// BenchmarkTopK 对比选择算法与完整排序取前 k 个的性能
+func BenchmarkTopK(b *testing.B) {
+	orders := randomOrders(1 << 18)
+	b.Run("FullSort", func(b *testing.B) {
+		benchmarkOnCopy(b, orders, func(s []BestPracticeOrder) {
+			slices.SortFunc(s, OrderByAmount.Reverse())
+		})
+	})
+	for _, k := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("TopK/k=%d", k), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				TopK(orders, k, OrderByAmount)
+			}
+		})
+		b.Run(fmt.Sprintf("PartialSort/k=%d", k), func(b *testing.B) {
+			benchmarkOnCopy(b, orders, func(s []BestPracticeOrder) {
+				PartialSort(s, k, OrderByAmount.Reverse())
+			})
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// 语义化版本（SemVer 2.0.0）与点分数字版本的比较
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion 版本号格式错误，具体原因见包装它的错误信息
+var ErrInvalidVersion = errors.New("无效的版本号")
+
+// SemVer 语义化版本 MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]
+type SemVer struct {
+	Major, Minor, Patch uint64
+	// Prerelease 先行版本标识符，例如 "rc.1" 解析为 ["rc", "1"]
+	Prerelease []string
+	// Build 构建元数据，不参与优先级比较
+	Build []string
+}
+
+func versionError(input, format string, args ...interface{}) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidVersion, input, fmt.Sprintf(format, args...))
+}
+
+// ParseSemVer 按 SemVer 2.0.0 规范严格解析版本号
+func ParseSemVer(s string) (SemVer, error) {
+	return parseSemVer(s, s, false)
+}
+
+// ParseSemVerLenient 宽松解析常见的发布标签：允许首尾空白和 v/V 前缀，
+// 缺省的次版本号和修订号补 0（"v1.2" 解析为 1.2.0），主版本号等数字段允许前导零
+func ParseSemVerLenient(s string) (SemVer, error) {
+	trimmed := strings.TrimSpace(s)
+	trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "v"), "V")
+	return parseSemVer(s, trimmed, true)
+}
+
+func parseSemVer(input, s string, lenient bool) (SemVer, error) {
+	var v SemVer
+	core := s
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		build, err := parseIdentifiers(input, core[i+1:], "构建元数据", false)
+		if err != nil {
+			return v, err
+		}
+		v.Build = build
+		core = core[:i]
+	}
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		pre, err := parseIdentifiers(input, core[i+1:], "先行版本号", true)
+		if err != nil {
+			return v, err
+		}
+		v.Prerelease = pre
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 || (!lenient && len(parts) != 3) {
+		return v, versionError(input, "版本核心应为 MAJOR.MINOR.PATCH 三段，实际为 %d 段", len(parts))
+	}
+	numbers := [3]uint64{}
+	names := [3]string{"主版本号", "次版本号", "修订号"}
+	for i, part := range parts {
+		if part == "" || !isASCIIDigits(part) {
+			return v, versionError(input, "%s %q 不是非负整数", names[i], part)
+		}
+		if !lenient && len(part) > 1 && part[0] == '0' {
+			return v, versionError(input, "%s %q 不能有前导零", names[i], part)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, versionError(input, "%s %q 超出范围", names[i], part)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+// parseIdentifiers 解析以点分隔的标识符；先行版本号中的纯数字标识符不能有前导零
+func parseIdentifiers(input, s, what string, numericNoLeadingZero bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if id == "" {
+			return nil, versionError(input, "%s包含空标识符", what)
+		}
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return nil, versionError(input, "%s标识符 %q 只能包含字母、数字和连字符", what, id)
+			}
+		}
+		if numericNoLeadingZero && isASCIIDigits(id) && len(id) > 1 && id[0] == '0' {
+			return nil, versionError(input, "%s的数字标识符 %q 不能有前导零", what, id)
+		}
+	}
+	return ids, nil
+}
+
+func isASCIIDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Compare 按 SemVer 优先级比较：依次比较主、次、修订号；有先行版本号的版本低于正式版本；
+// 先行版本号逐个标识符比较（数字按数值，数字低于字母数字，字母数字按 ASCII 序，标识符多者更高）；
+// 构建元数据不参与比较
+func (v SemVer) Compare(o SemVer) int {
+	if r := cmp.Or(cmp.Compare(v.Major, o.Major), cmp.Compare(v.Minor, o.Minor), cmp.Compare(v.Patch, o.Patch)); r != 0 {
+		return r
+	}
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < min(len(v.Prerelease), len(o.Prerelease)); i++ {
+		if r := comparePrereleaseIdentifier(v.Prerelease[i], o.Prerelease[i]); r != 0 {
+			return r
+		}
+	}
+	return cmp.Compare(len(v.Prerelease), len(o.Prerelease))
+}
+
+func comparePrereleaseIdentifier(a, b string) int {
+	numA, numB := isASCIIDigits(a), isASCIIDigits(b)
+	switch {
+	case numA && numB:
+		// 数字标识符没有前导零，位数多者数值大，无需担心溢出
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	case numA:
+		return -1
+	case numB:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// String 返回规范格式的版本号
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// CompareSemVer 宽松解析并比较两个版本标签，任一解析失败时返回错误
+func CompareSemVer(a, b string) (int, error) {
+	va, errA := ParseSemVerLenient(a)
+	vb, errB := ParseSemVerLenient(b)
+	if err := errors.Join(errA, errB); err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// SortVersions 宽松解析并按 SemVer 优先级升序排序版本标签，优先级相同时按原始字符串排序。
+// 无法解析的标签保持原有相对顺序排在最后，其解析错误合并后返回
+func SortVersions(tags []string) error {
+	type parsed struct {
+		tag     string
+		version SemVer
+		err     error
+	}
+	items := make([]parsed, len(tags))
+	var errs []error
+	for i, tag := range tags {
+		v, err := ParseSemVerLenient(tag)
+		items[i] = parsed{tag, v, err}
+		errs = append(errs, err)
+	}
+	slices.SortStableFunc(items, func(a, b parsed) int {
+		switch {
+		case a.err != nil || b.err != nil:
+			return cmp.Compare(btoi(a.err != nil), btoi(b.err != nil))
+		default:
+			return cmp.Or(a.version.Compare(b.version), strings.Compare(a.tag, b.tag))
+		}
+	})
+	for i, item := range items {
+		tags[i] = item.tag
+	}
+	return errors.Join(errs...)
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// CompareDotted 比较点分数字版本（如 Windows 构建号 "10.0.19041"）：逐段按数值比较，
+// 数字长度不受限制，段数不同时缺失的段按 0 比较；各段数值都相同时段数少者在前，
+// 因此 "1.2" < "1.2.0" < "1.2.0.0"，保证结果确定。
+// 出现空段或非数字段时返回错误
+func CompareDotted(a, b string) (int, error) {
+	partsA, errA := splitDotted(a)
+	partsB, errB := splitDotted(b)
+	if err := errors.Join(errA, errB); err != nil {
+		return 0, err
+	}
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		pa, pb := "0", "0"
+		if i < len(partsA) {
+			pa = partsA[i]
+		}
+		if i < len(partsB) {
+			pb = partsB[i]
+		}
+		if r := cmp.Or(cmp.Compare(len(pa), len(pb)), strings.Compare(pa, pb)); r != 0 {
+			return r, nil
+		}
+	}
+	return cmp.Compare(len(partsA), len(partsB)), nil
+}
+
+// splitDotted 拆分点分数字并去掉各段的前导零
+func splitDotted(s string) ([]string, error) {
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		if !isASCIIDigits(part) {
+			return nil, versionError(s, "第 %d 段 %q 不是非负整数", i+1, part)
+		}
+		trimmed := strings.TrimLeft(part, "0")
+		if trimmed == "" {
+			trimmed = "0"
+		}
+		parts[i] = trimmed
+	}
+	return parts, nil
+}
+
+// demonstrateVersionSorting 演示发布标签和点分版本号的排序及错误报告
+func demonstrateVersionSorting() {
+	fmt.Println("4.2 版本号排序:")
+	fmt.Println()
+	tags := []string{
+		"v1.10.0", "1.2.3-rc.1", "v1.2", "1.2.3", "1.2.3-alpha", "1.2.3-alpha.10",
+		"1.2.3-alpha.2", "1.2.3-alpha.beta", "1.2.3+build.5", "release-2024", "1.0.0-01",
+	}
+	naive := slices.Clone(tags)
+	slices.Sort(naive)
+	fmt.Printf("  字符串排序: %s\n", strings.Join(naive, ", "))
+
+	err := SortVersions(tags)
+	fmt.Printf("  SemVer 排序: %s\n", strings.Join(tags, ", "))
+	if err != nil {
+		fmt.Println("  解析错误:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+
+	builds := []string{"10.0.19041", "10.0.9200", "6.3.9600", "10.0.19041.1", "10.0.022000"}
+	slices.SortFunc(builds, func(a, b string) int {
+		r, _ := CompareDotted(a, b) // 以上均为合法的点分数字
+		return r
+	})
+	fmt.Printf("  点分数字排序: %s\n", strings.Join(builds, ", "))
+	if _, err := CompareDotted("10.0.x", "10.0.1"); err != nil {
+		fmt.Printf("  点分数字错误: %v\n", err)
+	}
+	fmt.Println()
+}
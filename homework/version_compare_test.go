@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// SemVer 2.0.0 §11 给出的优先级链
+func TestSemVerPrecedence(t *testing.T) {
+	chain := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i, a := range chain {
+		for j, b := range chain {
+			got, err := CompareSemVer(a, b)
+			if want := sign(i - j); err != nil || got != want {
+				t.Errorf("CompareSemVer(%q, %q) = %d, %v; 期望 %d", a, b, got, err, want)
+			}
+		}
+	}
+
+	shuffled := slices.Clone(chain)
+	slices.Reverse(shuffled)
+	if err := SortVersions(shuffled); err != nil || !slices.Equal(shuffled, chain) {
+		t.Errorf("SortVersions = %v, %v; 期望 %v", shuffled, err, chain)
+	}
+}
+
+func TestSemVerBuildMetadataIgnored(t *testing.T) {
+	for _, pair := range [][2]string{
+		{"1.0.0+build.1", "1.0.0+build.2"},
+		{"1.0.0", "1.0.0+20130313144700"},
+		{"1.0.0-beta+exp.sha.5114f85", "1.0.0-beta"},
+	} {
+		if got, err := CompareSemVer(pair[0], pair[1]); err != nil || got != 0 {
+			t.Errorf("CompareSemVer(%q, %q) = %d, %v; 期望 0", pair[0], pair[1], got, err)
+		}
+	}
+	v, err := ParseSemVer("1.0.0-rc.1+build.5")
+	if err != nil || !slices.Equal(v.Build, []string{"build", "5"}) || v.String() != "1.0.0-rc.1+build.5" {
+		t.Errorf("ParseSemVer 保留构建元数据: %+v, %v", v, err)
+	}
+
+	// 优先级相同时 SortVersions 按原始字符串决胜，结果与输入顺序无关
+	tags := []string{"1.0.0+b", "1.0.0", "1.0.0+a"}
+	if err := SortVersions(tags); err != nil || !slices.Equal(tags, []string{"1.0.0", "1.0.0+a", "1.0.0+b"}) {
+		t.Errorf("SortVersions = %v, %v", tags, err)
+	}
+}
+
+func TestParseSemVerStrictAndLenient(t *testing.T) {
+	for _, bad := range []string{
+		"01.2.3", "1.02.3", "1.2.03", // 版本核心的前导零
+		"1.2.3-01", "1.2.3-rc.007", // 数字先行版本标识符的前导零
+		"1.2.3-", "1.2.3-rc..1", "1.2.3-rc.", // 空的先行版本标识符
+		"1.2.3+", "1.2.3+a..b", // 空的构建元数据标识符
+		"1.2.3-rc_1", "1.2", "v1.2.3", " 1.2.3", "1.2.3.4", "1.-2.3",
+		"1.2.99999999999999999999",
+	} {
+		if _, err := ParseSemVer(bad); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseSemVer(%q) 错误 = %v, 期望 ErrInvalidVersion", bad, err)
+		}
+	}
+	// 构建元数据中的数字标识符允许前导零
+	if _, err := ParseSemVer("1.2.3+001"); err != nil {
+		t.Errorf("ParseSemVer(1.2.3+001): %v", err)
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"v1.2", "1.2.0"},
+		{"V1", "1.0.0"},
+		{" v1.2.3-rc.1 ", "1.2.3-rc.1"},
+		{"01.02.03", "1.2.3"},
+	}
+	for _, tt := range tests {
+		v, err := ParseSemVerLenient(tt.in)
+		if err != nil || v.String() != tt.want {
+			t.Errorf("ParseSemVerLenient(%q) = %v, %v; 期望 %s", tt.in, v, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "v", "1..2", "1.2.3-01", "1.2.3-", "release-1"} {
+		if _, err := ParseSemVerLenient(bad); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseSemVerLenient(%q) 错误 = %v, 期望 ErrInvalidVersion", bad, err)
+		}
+	}
+}
+
+func TestSortVersionsReportsInvalidTags(t *testing.T) {
+	tags := []string{"v2.0.0", "latest", "v1.10.0", "1.2.3-01", "v1.2"}
+	err := SortVersions(tags)
+	if want := []string{"v1.2", "v1.10.0", "v2.0.0", "latest", "1.2.3-01"}; !slices.Equal(tags, want) {
+		t.Errorf("SortVersions = %v, 期望 %v", tags, want)
+	}
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("错误 = %v, 期望 ErrInvalidVersion", err)
+	}
+	for _, bad := range []string{`"latest"`, `"1.2.3-01"`} {
+		if !strings.Contains(err.Error(), bad) {
+			t.Errorf("错误信息 %q 未指出无法解析的标签 %s", err, bad)
+		}
+	}
+	if strings.Contains(err.Error(), `"v1.2"`) {
+		t.Errorf("错误信息 %q 不应包含可以解析的标签", err)
+	}
+}
+
+func TestCompareDotted(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2", "1.2", 0},
+		{"1.2", "1.10", -1},
+		{"10.0.19041", "10.0.9200", 1},
+		{"1.02", "1.2", 0},
+		{"1.2", "1.2.0", -1},
+		{"1.2.0", "1.2", 1},
+		{"1.2.0", "1.2.0.0", -1},
+		{"1.2", "1.2.1", -1},
+		{"99999999999999999999.1", "99999999999999999999.0", 1},
+	}
+	for _, tt := range tests {
+		got, err := CompareDotted(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("CompareDotted(%q, %q) = %d, %v; 期望 %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "1..2", "1.a"} {
+		if _, err := CompareDotted(bad, "1"); err == nil {
+			t.Errorf("CompareDotted(%q) 期望返回错误", bad)
+		}
+	}
+}